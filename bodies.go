@@ -0,0 +1,46 @@
+package celest
+
+import (
+	"math"
+
+	"github.com/busoc/inspect/ephem"
+)
+
+// SunDirection gives the unit vector from the satellite to the sun in the TEME
+// frame.
+func (p Point) SunDirection() []float64 {
+	x, y, z := ephem.Sun(p.When)
+	return unit([]float64{x - p.Lat, y - p.Lon, z - p.Alt})
+}
+
+// MoonDirection gives the unit vector from the satellite to the moon in the
+// TEME frame.
+func (p Point) MoonDirection() []float64 {
+	x, y, z := ephem.Moon(p.When)
+	return unit([]float64{x - p.Lat, y - p.Lon, z - p.Alt})
+}
+
+// Beta gives the angle (degrees) between the orbital plane of the satellite and
+// the direction of the sun. It is positive when the sun is on the side of the
+// angular momentum of the orbit.
+func (p Point) Beta() float64 {
+	x, y, z := ephem.Sun(p.When)
+	var (
+		sun    = unit([]float64{x, y, z})
+		normal = unit(cross([]float64{p.Lat, p.Lon, p.Alt}, []float64{p.Vx, p.Vy, p.Vz}))
+	)
+	return math.Asin(dot(normal, sun)) * rad2deg
+}
+
+// MoonLimb gives the angle (degrees) between the moon and the limb of the earth
+// seen from the satellite. It is negative when the moon is behind the disk of
+// the earth.
+func (p Point) MoonLimb() float64 {
+	var (
+		pos   = []float64{p.Lat, p.Lon, p.Alt}
+		nadir = unit([]float64{-p.Lat, -p.Lon, -p.Alt})
+		sep   = math.Acos(dot(p.MoonDirection(), nadir))
+		earth = math.Asin(earthRadius / 1000 / norm(pos))
+	)
+	return (sep - earth) * rad2deg
+}
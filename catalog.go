@@ -0,0 +1,86 @@
+package celest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// alpha5 are the letters used as first digit of a catalog number in the
+// Alpha-5 scheme (I and O are skipped to avoid confusion with 1 and 0).
+const alpha5 = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ParseCatalog gives the catalog number of a satellite given in decimal or in
+// the Alpha-5 scheme where the first digit is replaced by a letter for numbers
+// in the range 100000-339999 (A0001 is 100001).
+func ParseCatalog(str string) (int, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, fmt.Errorf("empty catalog number")
+	}
+	ix := strings.IndexByte(alpha5, str[0])
+	if ix < 0 {
+		return strconv.Atoi(str)
+	}
+	if len(str) != 5 {
+		return 0, fmt.Errorf("invalid alpha-5 catalog number %s", str)
+	}
+	n, err := strconv.Atoi(str[1:])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid alpha-5 catalog number %s", str)
+	}
+	return (ix+10)*10000 + n, nil
+}
+
+// FormatCatalog gives the catalog number of a satellite in the Alpha-5 scheme
+// when it does not fit in five digits.
+func FormatCatalog(sid int) string {
+	if sid < 100000 || sid >= (len(alpha5)+10)*10000 {
+		return strconv.Itoa(sid)
+	}
+	return fmt.Sprintf("%c%04d", alpha5[sid/10000-10], sid%10000)
+}
+
+// Catalog groups the trajectories of all the satellites found in one or more
+// files of elements.
+type Catalog struct {
+	Mode ParseMode
+
+	// Number of elements skipped because their rows are malformed, they could
+	// not be parsed or their drag coefficient exceeds the limit
+	Skipped int
+
+	trajectories []*Trajectory
+	index        map[int]*Trajectory
+}
+
+// ScanFormat reads the elements of all the satellites from r given in format
+// (tle, xml, json, csv or kvn). The format is detected from the content of r
+// when empty. Invalid elements and malformed rows are skipped and counted.
+func (c *Catalog) ScanFormat(r io.Reader, format string, bstar float64) error {
+	if c.index == nil {
+		c.index = make(map[int]*Trajectory)
+	}
+	return scanElements(r, format, c.Mode, func(e *Element, err error) error {
+		if err != nil || math.Abs(e.BStar) > math.Abs(bstar) {
+			c.Skipped++
+			return nil
+		}
+		t, ok := c.index[e.Sid]
+		if !ok {
+			t = &Trajectory{Mode: c.Mode}
+			c.index[e.Sid] = t
+			c.trajectories = append(c.trajectories, t)
+		}
+		t.elements = append(t.elements, e)
+		return nil
+	})
+}
+
+// Trajectories gives the trajectory of each satellite in the order in which
+// they have been found.
+func (c *Catalog) Trajectories() []*Trajectory {
+	return c.trajectories
+}
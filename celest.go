@@ -3,6 +3,11 @@ package celest
 import (
 	"math"
 	"time"
+
+	"github.com/busoc/inspect/coord"
+	"github.com/busoc/inspect/eop"
+	"github.com/busoc/inspect/ephem"
+	"github.com/busoc/inspect/timescale"
 )
 
 const (
@@ -20,10 +25,12 @@ const (
 	earthRadius = 6378.136 * 1000
 	// sunRadius   = 6.96033e8
 	sunRadius = 695700 * 1000
+	// earthRotation in radians per second
+	earthRotation = 7.292115146706979e-5
 )
 
 const (
-	rad2deg = math.Pi * 180.0
+	rad2deg = 180.0 / math.Pi
 	deg2rad = math.Pi / 180.0
 	xpdotp  = minPerDays / (2.0 * math.Pi)
 )
@@ -31,83 +38,37 @@ const (
 const (
 	deltaModJD    = 2400000.5
 	deltaCnesJD   = 2433282.5
-	deltaJ2000    = 2451545.0
 	deltaDublinJD = 2415020.0
-	jdByMil       = 36525.0
 )
 
 const Axis = 3
 
-func gstTimeBis(jd float64) float64 {
-	cjd := (jd - deltaJ2000) / jdByMil
-
-	h := modf(jd-deltaCnesJD, 24)
-	m := modf(h, 60)
-	s := modf(m, 60)
-
-	h = math.Floor(h) * secPerHours
-	m = math.Floor(m) * secPerMins
-
-	gha := 23925.836 + 8640184.542*cjd + 0.092*cjd*cjd + (h + m + s)
-	gst := gha * (360.0 / secPerDays)
-	gst -= math.Floor(gst/360.0) * 360.0
-
-	return gst / 180.0 * math.Pi
-}
-
-func modf(f, x float64) float64 {
-	_, v := math.Modf(f)
-	return v * x
-}
-
-func gstTime(t time.Time) float64 {
-	jd, _, _ := mjdTime(t)
-	cjd := (jd - deltaDublinJD) / jdByMil
-	h, m, s := float64(t.Hour())*secPerHours, float64(t.Minute())*secPerMins, float64(t.Second())
-
-	gha := 23925.836 + 8640184.542*cjd + 0.092*cjd*cjd + (h + m + s)
-	gst := gha * (360.0 / secPerDays)
-	gst -= math.Floor(gst/360.0) * 360.0
-
-	return gst / 180.0 * math.Pi
-}
-
-func mjdTime(t time.Time) (float64, float64, float64) {
-	y, m, d := float64(t.Year()), float64(t.Month()), float64(t.Day())
-	h, i, s, ms := float64(t.Hour()), float64(t.Minute()), float64(t.Second()), float64(t.Nanosecond())/1000
-
-	f := ((ms / math.Pow10(9)) + s + (i * secPerMins) + (h * secPerHours)) / secPerDays
-	c := math.Trunc((m - 14) / 12)
-
-	jd := d - 32075 + math.Floor(1461*(y+4800+c)/4)
-	jd += math.Floor(367 * (m - 2 - c*12) / 12)
-	jd -= math.Floor(3 * (math.Floor(y+4900+c) / 100) / 4)
-	jd += f - 0.5
-
-	return jd, jd - deltaCnesJD, (jd - deltaCnesJD) / jdByMil
+// orientation gives the earth orientation parameters of the table set with
+// eop.Use or of the snapshot embedded in eop.
+func orientation(w time.Time) coord.EOP {
+	return eop.Current().At(w)
 }
 
+// JD gives the julian date of t.
 func JD(t time.Time) float64 {
-	jd, _, _ := mjdTime(t)
-	return jd
+	return coord.JulianDate(t)
 }
 
+// MJD50 gives the CNES julian date (days since 1950-01-01) of t.
 func MJD50(t time.Time) float64 {
-	_, jd, _ := mjdTime(t)
-	return jd
+	return timescale.JD50(t)
 }
 
+// MJD70 gives the modified julian date of t.
 func MJD70(t time.Time) float64 {
-	jd, _, _ := mjdTime(t)
-	return jd - deltaModJD
+	return timescale.MJD(t)
 }
 
+// ConvertTEME gives the latitude, longitude (degrees) and altitude (meters)
+// above a spherical earth of the position (kilometers) given in the TEME frame.
 func ConvertTEME(t time.Time, teme []float64) (float64, float64, float64) {
-	ts := make([]float64, Axis)
-	for i := range teme {
-		ts[i] = teme[i] * 1000
-	}
-	return ConvertECEF(ecefCoordinates(gstTime(t), ts))
+	x, y, z := coord.Teme2ITRF(t, orientation(t), teme[0]*1000, teme[1]*1000, teme[2]*1000)
+	return ConvertECEF([]float64{x, y, z})
 }
 
 func ConvertECEF(rs []float64) (float64, float64, float64) {
@@ -122,39 +83,46 @@ func ConvertECEF(rs []float64) (float64, float64, float64) {
 	return lat, lon, norm - earthRadius
 }
 
-func ecefCoordinates(gst float64, teme []float64) []float64 {
-	cos, sin := math.Cos(gst), math.Sin(gst)
-	x, y := teme[0], teme[1]
+func groundSpeed(ps, vs []float64) float64 {
+	radius := norm(ps)
 
-	rs := make([]float64, Axis)
-	rs[0] = cos*x + sin*y
-	rs[1] = -sin*x + cos*y
-	rs[2] = teme[2]
+	var radial float64
+	for i := range ps {
+		radial += ps[i] * vs[i]
+	}
+	radial /= radius
 
-	return rs
+	speed := norm(vs)
+	speed = speed*speed - radial*radial
+	if speed <= 0 {
+		return 0
+	}
+	return math.Sqrt(speed) * (earthRadius / 1000) / radius
 }
 
+func norm(vs []float64) float64 {
+	var n float64
+	for i := range vs {
+		n += vs[i] * vs[i]
+	}
+	return math.Sqrt(n)
+}
+
+// sunPosition gives the positions (meters) of the sun in the TEME frame at the
+// julian dates ws.
 func sunPosition(ws []float64) [][]float64 {
-	const (
-		omega   = 282.9400
-		epsilon = 23.43929111 / 180 * math.Pi
-	)
 	ps := make([][]float64, len(ws))
 	for i := range ws {
-		cjd := (ws[i] - deltaJ2000) / jdByMil
-		m := 357.5256 + 35999.049*cjd
-		ecliptic := omega + m + (6892 / secPerHours * math.Sin(m/180*math.Pi)) + (72 / secPerHours * math.Sin(2*m/180*math.Pi))
-		distance := (149.619 - (2.499 * math.Cos(m/180*math.Pi)) - (0.021 * math.Cos(2*m/180*math.Pi))) * math.Pow10(9)
-
-		lat := distance * math.Cos(ecliptic/180*math.Pi)
-		lon := distance * math.Sin(ecliptic/180*math.Pi) * math.Cos(epsilon)
-		alt := distance * math.Sin(ecliptic/180*math.Pi) * math.Sin(epsilon)
-		ps[i] = []float64{lat, lon, alt}
+		x, y, z := ephem.Sun(coord.FromJulianDate(ws[i]))
+		ps[i] = []float64{x * 1000, y * 1000, z * 1000}
 	}
 	return ps
 }
 
-func eclipseStatus(ps [][]float64, ws []float64) ([]bool, []bool) {
+// eclipseStatus gives for each position (TEME in meters) if the satellite is in
+// the umbra or in the penumbra of the earth and the fraction of the solar disk
+// occulted by the earth.
+func eclipseStatus(ps [][]float64, ws []float64) ([]bool, []bool, []float64) {
 	sun := sunPosition(ws)
 	t1 := make([][]float64, len(ps))
 	t2 := make([][]float64, len(ps))
@@ -184,7 +152,10 @@ func eclipseStatus(ps [][]float64, ws []float64) ([]bool, []bool) {
 	}
 	fes := make([]bool, len(ps))
 	pes := make([]bool, len(ps))
+	ocs := make([]float64, len(ps))
 	for i := range fes {
+		ocs[i] = occultation(earthAngles[i], sunAngles[i], earthSunAngles[i])
+
 		fa := earthSunAngles[i] < math.Abs(earthAngles[i]-sunAngles[i])
 		fb := earthAngles[i] > sunAngles[i]
 		fes[i] = fa && fb
@@ -194,7 +165,26 @@ func eclipseStatus(ps [][]float64, ws []float64) ([]bool, []bool) {
 		pes[i] = pa && pb
 	}
 
-	return fes, pes
+	return fes, pes, ocs
+}
+
+// occultation gives the fraction of the solar disk (of angular radius sun)
+// covered by the disk of the earth (of angular radius earth) when their centres
+// are separated by the angle sep.
+func occultation(earth, sun, sep float64) float64 {
+	switch {
+	case sep >= earth+sun:
+		return 0
+	case sep <= earth-sun:
+		return 1
+	case sep <= sun-earth:
+		return (earth * earth) / (sun * sun)
+	}
+	a := earth * earth * math.Acos((sep*sep+earth*earth-sun*sun)/(2*sep*earth))
+	b := sun * sun * math.Acos((sep*sep+sun*sun-earth*earth)/(2*sep*sun))
+	c := 0.5 * math.Sqrt((-sep+earth+sun)*(sep+earth-sun)*(sep-earth+sun)*(sep+earth+sun))
+
+	return (a + b - c) / (math.Pi * sun * sun)
 }
 
 func normsArray(ps [][]float64) []float64 {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/busoc/inspect"
+)
+
+// Diff is the difference between two states at the same time: the distance
+// (kilometers) with its radial, in-track and cross-track components in the
+// orbital frame of the first state and the relative velocity
+// (kilometers/second).
+type Diff struct {
+	When       time.Time
+	Distance   float64
+	Radial     float64
+	InTrack    float64
+	CrossTrack float64
+	Speed      float64
+}
+
+// Compare gives the differences between the states of ss and os at the times
+// of ss. The states of os are interpolated between the two states surrounding
+// each time. The times of ss outside the range of os are skipped.
+func Compare(ss, os []State) []Diff {
+	sort.Slice(ss, func(i, j int) bool { return ss[i].When.Before(ss[j].When) })
+	sort.Slice(os, func(i, j int) bool { return os[i].When.Before(os[j].When) })
+
+	var (
+		ds []Diff
+		j  int
+	)
+	for _, s := range ss {
+		for j < len(os)-1 && !os[j+1].When.After(s.When) {
+			j++
+		}
+		if j >= len(os) || os[j].When.After(s.When) {
+			continue
+		}
+		o := os[j]
+		if !o.When.Equal(s.When) {
+			if j == len(os)-1 {
+				continue
+			}
+			o = interpolate(os[j], os[j+1], s.When)
+		}
+		ds = append(ds, diff(s, o))
+	}
+	return ds
+}
+
+func diff(s, o State) Diff {
+	dr := sub(o.Pos, s.Pos)
+	d := Diff{
+		When:     s.When,
+		Distance: norm(dr),
+		Speed:    norm(sub(o.Vel, s.Vel)),
+	}
+	d.Radial, d.InTrack, d.CrossTrack = celest.OrbitalFrame(s.Pos, s.Inertial(), dr)
+	return d
+}
+
+// interpolate gives the state at w between a and b with a cubic Hermite
+// interpolation of the positions and velocities.
+func interpolate(a, b State, w time.Time) State {
+	h := b.When.Sub(a.When).Seconds()
+	t := w.Sub(a.When).Seconds() / h
+
+	t2, t3 := t*t, t*t*t
+	var (
+		h00, h10, h01, h11 = 2*t3 - 3*t2 + 1, t3 - 2*t2 + t, -2*t3 + 3*t2, t3 - t2
+		d00, d10, d01, d11 = 6*t2 - 6*t, 3*t2 - 4*t + 1, -6*t2 + 6*t, 3*t2 - 2*t
+	)
+	s := State{
+		When: w,
+		Pos:  make([]float64, 3),
+		Vel:  make([]float64, 3),
+	}
+	for i := 0; i < 3; i++ {
+		s.Pos[i] = h00*a.Pos[i] + h10*h*a.Vel[i] + h01*b.Pos[i] + h11*h*b.Vel[i]
+		s.Vel[i] = (d00*a.Pos[i] + d10*h*a.Vel[i] + d01*b.Pos[i] + d11*h*b.Vel[i]) / h
+	}
+	return s
+}
+
+// Stats summarizes the distances between two trajectories. Drift is the slope
+// (kilometers/day) of the least squares line fitted to the distances.
+type Stats struct {
+	Count int
+	Mean  float64
+	Max   float64
+	RMS   float64
+	Drift float64
+
+	// Time of the maximum distance
+	When time.Time
+}
+
+func Summarize(ds []Diff) Stats {
+	var s Stats
+	if len(ds) == 0 {
+		return s
+	}
+	var (
+		first         = ds[0].When
+		sx, sy        float64
+		sxx, sxy, syy float64
+	)
+	for i, d := range ds {
+		x := d.When.Sub(first).Hours() / 24
+		sx, sy = sx+x, sy+d.Distance
+		sxx, sxy, syy = sxx+x*x, sxy+x*d.Distance, syy+d.Distance*d.Distance
+		if i == 0 || d.Distance > s.Max {
+			s.Max, s.When = d.Distance, d.When
+		}
+	}
+	n := float64(len(ds))
+	s.Count = len(ds)
+	s.Mean = sy / n
+	s.RMS = math.Sqrt(syy / n)
+	if den := n*sxx - sx*sx; den != 0 {
+		s.Drift = (n*sxy - sx*sy) / den
+	}
+	return s
+}
+
+func sub(a, b []float64) []float64 {
+	return []float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func norm(vs []float64) float64 {
+	return math.Sqrt(vs[0]*vs[0] + vs[1]*vs[1] + vs[2]*vs[2])
+}
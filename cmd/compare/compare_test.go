@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleCompare() {
+	var (
+		w   = time.Date(2018, 10, 31, 8, 0, 0, 0, time.UTC)
+		vel = []float64{0, 7.5, 0}
+		ss  []State
+		os  []State
+	)
+	for i := 0; i <= 2; i++ {
+		t := float64(i * 30)
+		ss = append(ss, State{
+			When: w.Add(time.Duration(i) * 30 * time.Second),
+			Pos:  []float64{7000, 7.5 * t, 0},
+			Vel:  vel,
+		})
+	}
+	for i := 0; i <= 1; i++ {
+		t := float64(i * 60)
+		os = append(os, State{
+			When: w.Add(time.Duration(i) * time.Minute),
+			Pos:  []float64{7000.5, 7.5*t + 1, -0.2},
+			Vel:  vel,
+		})
+	}
+	for _, d := range Compare(ss, os) {
+		fmt.Printf("%s: %.3f km (%.3f, %.3f, %.3f)\n", d.When.Format("15:04:05"), d.Distance, d.Radial, d.InTrack, d.CrossTrack)
+	}
+	// Output:
+	// 08:00:00: 1.136 km (0.500, 1.000, -0.200)
+	// 08:00:30: 1.136 km (0.532, 0.983, -0.200)
+	// 08:01:00: 1.136 km (0.563, 0.966, -0.200)
+}
+
+func Example_interpolate() {
+	// x(t) = t³ is reproduced exactly by the cubic Hermite interpolation
+	var (
+		w = time.Date(2018, 10, 31, 8, 0, 0, 0, time.UTC)
+		a = State{When: w, Pos: []float64{0, 0, 0}, Vel: []float64{0, 0, 0}}
+		b = State{When: w.Add(2 * time.Second), Pos: []float64{8, 0, 0}, Vel: []float64{12, 0, 0}}
+	)
+	s := interpolate(a, b, w.Add(time.Second))
+	fmt.Printf("pos: %.3f, vel: %.3f", s.Pos[0], s.Vel[0])
+	// Output:
+	// pos: 1.000, vel: 3.000
+}
+
+func ExampleSummarize() {
+	w := time.Date(2018, 10, 31, 0, 0, 0, 0, time.UTC)
+	ds := []Diff{
+		{When: w, Distance: 1},
+		{When: w.Add(24 * time.Hour), Distance: 2},
+		{When: w.Add(48 * time.Hour), Distance: 3},
+	}
+	s := Summarize(ds)
+	fmt.Printf("count: %d, mean: %.3f, max: %.3f (%s), rms: %.3f, drift: %.3f km/day", s.Count, s.Mean, s.Max, s.When.Format("2006-01-02"), s.RMS, s.Drift)
+	// Output:
+	// count: 3, mean: 2.000, max: 3.000 (2018-11-02), rms: 2.160, drift: 1.000 km/day
+}
+
+func Example_parseColumns() {
+	for _, h := range []string{
+		"time, mjd, altitude, latitude, longitude, eclipse, saa, epoch, vx, vy, vz, ground, speed",
+		"sid, time, mjd, altitude, latitude, longitude, eclipse, saa, epoch, vx, vy, vz",
+		"time, mjd, altitude, latitude, longitude, eclipse, saa",
+	} {
+		cs, err := parseColumns(h)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(cs)
+	}
+	// Output:
+	// [0 1 2 3 4 8 9 10]
+	// [1 2 3 4 5 9 10 11]
+	// column vx not found in header
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect/eop"
+	"github.com/busoc/inspect/timescale"
+	"github.com/midbel/linewriter"
+)
+
+var (
+	BuildVersion = "0.1.0"
+	BuildTime    = "2026-10-17"
+)
+
+const help = `compare takes two trajectories, as generated by inspect or propagated from
+two sets of elements (TLE or OMM), and gives the distance between the positions
+of both trajectories at each time of the first one.
+
+the positions of the second trajectory are interpolated between its two points
+surrounding each time of the first trajectory (cubic Hermite interpolation of
+the positions and velocities) so that both trajectories do not need to share
+the same times or interval.
+
+the trajectories written by inspect can be in csv (with or without its header)
+or pipe format. The coordinate system of a trajectory is read from the header
+of a csv file or given with -c (the same as the -c option of inspect). In the
+same way, the time scale of a trajectory is read from the header of a csv file
+or given with -timescale (default to UTC). The columns of a trajectory are
+located from the header of a csv file and compare fails when one of them is
+missing. The pipe format of inspect has no header: its columns are expected in
+the order written by inspect and -c and -timescale must be given by hand when
+the trajectory is not in the default system and time scale. The elements are
+propagated from the base time (default to the epoch of the latest elements of
+both inputs) over the period given with -d every interval given with -i. It is
+used to measure how quickly two successive TLE diverge.
+
+the output of compare consists of multiple columns:
+
+* time
+* distance (km)
+* radial, in-track and cross-track components of the distance in the orbital
+  frame of the first trajectory (km)
+* relative velocity (km/s)
+
+the number of points compared, the mean, maximum and RMS of the distances and
+the drift rate (slope of the distances in km per day) are written at the end of
+the output (or alone with -summary).
+
+options:
+
+  c       COORD   coordinate system of the trajectories (geocentric, geodetic, teme/eci, j2000, gcrf, dublin, cnes)
+  b       DATE    base time of the trajectories propagated from elements
+  d       TIME    period of the trajectories propagated from elements
+  i       TIME    interval of the trajectories propagated from elements
+  bstar   LIMIT   B-STAR drag coefficient limit of the elements
+  eop     FILE    earth orientation parameters (IERS finals2000A or CelesTrak CSV)
+  timescale SCALE time scale of the trajectories (utc, tai, tt, gps, ut1)
+  lenient         accept TLE with invalid checksum or without trailing spaces
+  csv             output distances as comma separated value
+  summary         only output the summary of the distances
+  version         print the version of compare and exit
+  help            print this help message and exit
+
+usages:
+$ compare [-c] [-b] [-d] [-i] [-bstar] [-eop] [-timescale] [-lenient] [-csv] [-summary] <first> <second>
+$ compare -version
+$ compare -help
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, strings.TrimSpace(help))
+		os.Exit(2)
+	}
+	var (
+		syst     = flag.String("c", "", "coordinate system")
+		base     = flag.String("b", "", "base time")
+		period   = flag.Duration("d", time.Hour*24, "period")
+		interval = flag.Duration("i", time.Minute, "interval")
+		bstar    = flag.Float64("bstar", -0.001, "bstar max")
+		file     = flag.String("eop", "", "earth orientation parameters")
+		scale    = flag.String("timescale", "", "time scale")
+		lenient  = flag.Bool("lenient", false, "lenient")
+		comma    = flag.Bool("csv", false, "csv")
+		summary  = flag.Bool("summary", false, "summary")
+		version  = flag.Bool("version", false, "version")
+		help     = flag.Bool("help", false, "help")
+	)
+	flag.Parse()
+
+	if *help {
+		flag.Usage()
+		return
+	}
+	if *version {
+		fmt.Fprintf(os.Stdout, "compare version %s (%s)", BuildVersion, BuildTime)
+		return
+	}
+	if flag.NArg() != 2 {
+		flag.Usage()
+	}
+
+	var bt time.Time
+	if *base != "" {
+		b, err := time.Parse(time.RFC3339, *base)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		bt = b
+	}
+	if *file != "" {
+		t, err := eop.Load(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		eop.Use(t)
+	}
+	if *syst == "gcrf" && !eop.Current().Offsets() {
+		fmt.Fprintln(os.Stderr, "no celestial pole offsets given with -eop, gcrf is j2000")
+	}
+	sc, err := timescale.Parse(*scale)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var ss []*Source
+	for _, a := range flag.Args() {
+		s, err := Open(a, *syst, sc, *bstar, *lenient)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if w := s.Epoch(); *base == "" && w.After(bt) {
+			bt = w
+		}
+		ss = append(ss, s)
+	}
+
+	var states [][]State
+	for _, s := range ss {
+		s.Base, s.Period, s.Interval = bt, *period, *interval
+		vs, err := s.States()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", s.File, err)
+			os.Exit(1)
+		}
+		states = append(states, vs)
+	}
+	ds := Compare(states[0], states[1])
+	if !*summary {
+		if err := printDiffs(Line(*comma), ds); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	printStats(os.Stdout, Summarize(ds), *comma)
+}
+
+func printDiffs(ws *linewriter.Writer, ds []Diff) error {
+	for _, d := range ds {
+		ws.AppendTime(d.When, "2006-01-02T15:04:05.000", linewriter.AlignLeft)
+		ws.AppendFloat(d.Distance, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(d.Radial, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(d.InTrack, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(d.CrossTrack, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(d.Speed, 8, 5, linewriter.AlignRight|linewriter.Float)
+
+		if _, err := io.Copy(os.Stdout, ws); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+func printStats(w io.Writer, s Stats, comma bool) {
+	prefix := ""
+	if comma {
+		prefix = "#"
+	}
+	fmt.Fprintf(w, "%spoints compared: %d\n", prefix, s.Count)
+	fmt.Fprintf(w, "%smean distance: %12.3fkm\n", prefix, s.Mean)
+	fmt.Fprintf(w, "%smaximum distance: %12.3fkm (%s)\n", prefix, s.Max, s.When.Format("2006-01-02T15:04:05.000"))
+	fmt.Fprintf(w, "%sRMS distance: %12.3fkm\n", prefix, s.RMS)
+	fmt.Fprintf(w, "%sdrift rate: %12.3fkm/day\n", prefix, s.Drift)
+}
+
+func Line(comma bool) *linewriter.Writer {
+	var opts []linewriter.Option
+	if comma {
+		opts = append(opts, linewriter.AsCSV(true))
+	} else {
+		opts = []linewriter.Option{
+			linewriter.WithPadding([]byte(" ")),
+			linewriter.WithSeparator([]byte("|")),
+		}
+	}
+	return linewriter.NewWriter(8192, opts...)
+}
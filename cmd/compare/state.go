@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/coord"
+	"github.com/busoc/inspect/eop"
+	"github.com/busoc/inspect/timescale"
+)
+
+const (
+	Radius   = 6378.136
+	Rotation = 7.292115146706979e-5
+)
+
+var layouts = []string{
+	"2006-01-02T15:04:05.000000",
+	"2006-01-02 15:04:05.000000",
+}
+
+// State is the position (kilometers) and velocity (kilometers/second) of the
+// satellite in the earth fixed frame.
+type State struct {
+	When time.Time
+	Pos  []float64
+	Vel  []float64
+}
+
+// Inertial gives the velocity of the satellite in a non rotating frame aligned
+// with the earth fixed frame at the time of the state.
+func (s State) Inertial() []float64 {
+	return []float64{
+		s.Vel[0] - Rotation*s.Pos[1],
+		s.Vel[1] + Rotation*s.Pos[0],
+		s.Vel[2],
+	}
+}
+
+// Source gives the states of a satellite from a file.
+type Source struct {
+	File  string
+	Syst  string
+	Scale timescale.Scale
+
+	// Base time, period and interval of the trajectory propagated from TLE
+	Base     time.Time
+	Period   time.Duration
+	Interval time.Duration
+	BStar    float64
+	Lenient  bool
+
+	trajectory *celest.Trajectory
+	states     []State
+	columns    columns
+}
+
+// Open reads file as a trajectory written by inspect (csv or pipe) or as a set
+// of elements (TLE or OMM) that is propagated later with States.
+func Open(file, syst string, scale timescale.Scale, bstar float64, lenient bool) (*Source, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s := Source{File: file, Syst: syst, Scale: scale, BStar: bstar, Lenient: lenient}
+	if isTrajectory(buf) {
+		err = s.readTrajectory(buf)
+	} else {
+		err = s.readElements(buf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return &s, nil
+}
+
+// Epoch gives the epoch of the last TLE of the source (zero for a trajectory).
+func (s *Source) Epoch() time.Time {
+	if s.trajectory == nil {
+		return time.Time{}
+	}
+	var when time.Time
+	for _, e := range s.trajectory.Elements() {
+		if e.When.After(when) {
+			when = e.When
+		}
+	}
+	return when
+}
+
+// States gives the states of the satellite sorted by time.
+func (s *Source) States() ([]State, error) {
+	if s.trajectory == nil {
+		return s.states, nil
+	}
+	s.trajectory.Base = s.Base
+	rs, err := s.trajectory.Predict(s.Period, s.Interval, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	var ss []State
+	for r := range rs {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		for _, p := range r.Points {
+			p := p.CNES()
+			ss = append(ss, State{
+				When: p.When,
+				Pos:  []float64{p.Lat, p.Lon, p.Alt},
+				Vel:  []float64{p.Vx, p.Vy, p.Vz},
+			})
+		}
+	}
+	return ss, nil
+}
+
+func (s *Source) readElements(buf []byte) error {
+	var t celest.Trajectory
+	if s.Lenient {
+		t.Mode = celest.Lenient
+	}
+	err := t.ScanFilter(bytes.NewReader(buf), "", s.BStar, func(*celest.Element) bool { return true })
+	if err != nil {
+		return err
+	}
+	if len(t.Elements()) == 0 {
+		return fmt.Errorf("no elements found")
+	}
+	s.trajectory = &t
+	return nil
+}
+
+// readTrajectory reads the rows of a trajectory. The system of the positions,
+// the time scale and the columns are given by the header of a csv file when
+// present.
+func (s *Source) readTrajectory(buf []byte) error {
+	const (
+		prefix = "#latlon system"
+		scale  = "#time scale"
+	)
+
+	rs := bufio.NewScanner(bytes.NewReader(buf))
+	for rs.Scan() {
+		line := strings.TrimSpace(rs.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			switch {
+			case strings.HasPrefix(line, prefix):
+				s.Syst = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			case strings.HasPrefix(line, scale):
+				sc, err := timescale.Parse(strings.TrimPrefix(line, scale))
+				if err != nil {
+					return err
+				}
+				s.Scale = sc
+			case isHeader(line[1:]):
+				cs, err := parseColumns(line[1:])
+				if err != nil {
+					return err
+				}
+				s.columns = cs
+			}
+			continue
+		}
+		st, err := s.parseRow(line)
+		if err != nil {
+			return err
+		}
+		s.states = append(s.states, st)
+	}
+	return rs.Err()
+}
+
+// parseRow gives the state from a row of a trajectory. Without header, the
+// columns are the ones of inspect and the row can start with the satellite
+// number (catalog mode of inspect).
+func (s *Source) parseRow(line string) (State, error) {
+	var st State
+	sep := ","
+	if strings.Contains(line, "|") {
+		sep = "|"
+	}
+	fs := strings.Split(line, sep)
+	for i := range fs {
+		fs[i] = strings.TrimSpace(fs[i])
+	}
+	cs := s.columns
+	if cs == nil {
+		cs = defaultColumns
+		if _, err := parseTime(fs[0]); err != nil && len(fs) > 1 {
+			fs = fs[1:]
+		}
+	}
+	if len(fs) <= cs.max() {
+		return st, fmt.Errorf("invalid row %q: not enough columns", line)
+	}
+	when, err := parseTime(fs[cs[0]])
+	if err != nil {
+		return st, err
+	}
+	vs := make([]float64, len(cs)-1)
+	for i, j := range cs[1:] {
+		vs[i], err = strconv.ParseFloat(fs[j], 64)
+		if err != nil {
+			return st, fmt.Errorf("invalid row %q: %s", line, err)
+		}
+	}
+	return toState(s.Scale.ToUTC(when), s.Syst, vs), nil
+}
+
+// columns gives the indices of the time, mjd, altitude, latitude, longitude
+// and velocity columns of a trajectory.
+type columns []int
+
+var (
+	columnNames    = []string{"time", "mjd", "altitude", "latitude", "longitude", "vx", "vy", "vz"}
+	defaultColumns = columns{0, 1, 2, 3, 4, 8, 9, 10}
+)
+
+// isHeader reports whether the comment line (without its leading #) gives the
+// names of the columns of a csv file.
+func isHeader(line string) bool {
+	ix := strings.Index(line, ",")
+	if ix < 0 {
+		return false
+	}
+	switch strings.TrimSpace(line[:ix]) {
+	case "time", "sid":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseColumns locates the columns of a trajectory from the names of the header
+// of a csv file.
+func parseColumns(header string) (columns, error) {
+	ix := make(map[string]int)
+	for i, h := range strings.Split(header, ",") {
+		ix[strings.TrimSpace(h)] = i
+	}
+	cs := make(columns, len(columnNames))
+	for i, n := range columnNames {
+		j, ok := ix[n]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in header", n)
+		}
+		cs[i] = j
+	}
+	return cs, nil
+}
+
+func (c columns) max() int {
+	var m int
+	for _, v := range c {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+// toState gives the state from the mjd, altitude, latitude, longitude and
+// velocity columns of a trajectory in the given system. In the cartesian
+// systems, the altitude, latitude and longitude columns give z, x and y.
+func toState(when time.Time, syst string, vs []float64) State {
+	st := State{When: when, Vel: vs[4:]}
+	alt, lat, lon := vs[1], vs[2], vs[3]
+	switch strings.ToLower(syst) {
+	case "teme", "eci":
+		st.Pos, st.Vel = fromTeme(when, lat, lon, alt, vs[4:])
+	case "j2000", "eme2000":
+		tt := timescale.TT.FromUTC(when)
+		x, y, z := coord.J20002Teme(tt, lat, lon, alt)
+		vx, vy, vz := coord.J20002Teme(tt, vs[4], vs[5], vs[6])
+		st.Pos, st.Vel = fromTeme(when, x, y, z, []float64{vx, vy, vz})
+	case "gcrf":
+		tt, eop := timescale.TT.FromUTC(when), eop.Current().At(when)
+		x, y, z := coord.GCRF2Teme(tt, eop, lat, lon, alt)
+		vx, vy, vz := coord.GCRF2Teme(tt, eop, vs[4], vs[5], vs[6])
+		st.Pos, st.Vel = fromTeme(when, x, y, z, []float64{vx, vy, vz})
+	case "dublin", "cnes":
+		st.Pos = []float64{lat, lon, alt}
+	case "geodetic":
+		x, y, z := coord.GeodeticToECEF(lat, lon, alt)
+		st.Pos = []float64{x, y, z}
+	case "geocentric":
+		st.Pos = fromGeocentric(lat, lon, alt)
+	default:
+		r := Radius + alt
+		lat, lon = lat*math.Pi/180, lon*math.Pi/180
+		st.Pos = []float64{
+			r * math.Cos(lat) * math.Cos(lon),
+			r * math.Cos(lat) * math.Sin(lon),
+			r * math.Sin(lat),
+		}
+	}
+	return st
+}
+
+// fromTeme gives the position and velocity in the earth fixed frame of the
+// position x, y, z and velocity vs in the TEME frame.
+func fromTeme(when time.Time, x, y, z float64, vs []float64) ([]float64, []float64) {
+	p := celest.Point{
+		When: when,
+		Lat:  x,
+		Lon:  y,
+		Alt:  z,
+		Vx:   vs[0],
+		Vy:   vs[1],
+		Vz:   vs[2],
+	}
+	p = p.CNES()
+	return []float64{p.Lat, p.Lon, p.Alt}, []float64{p.Vx, p.Vy, p.Vz}
+}
+
+// fromGeocentric gives the position in the earth fixed frame from the columns
+// of the geocentric system of inspect: the geocentric latitude of the point
+// below the satellite on the ellipsoid and the distance to the centre of the
+// earth minus its equatorial radius.
+func fromGeocentric(lat, lon, alt float64) []float64 {
+	const (
+		eex    = 0.006694385
+		radius = 6378.1363
+	)
+	lat = math.Atan(math.Tan(lat*math.Pi/180)/(1-eex)) * 180 / math.Pi
+
+	var x, y, z float64
+	for i, h := 0, alt; i < 5; i++ {
+		x, y, z = coord.GeodeticToECEF(lat, lon, h)
+		h += radius + alt - math.Sqrt(x*x+y*y+z*z)
+	}
+	return []float64{x, y, z}
+}
+
+func parseTime(str string) (time.Time, error) {
+	var err error
+	for _, f := range layouts {
+		var w time.Time
+		if w, err = time.Parse(f, str); err == nil {
+			return w, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// isTrajectory reports whether buf looks like a trajectory written by inspect:
+// a csv header or a first row starting with a time (after an optional
+// satellite number).
+func isTrajectory(buf []byte) bool {
+	rs := bufio.NewScanner(bytes.NewReader(buf))
+	for rs.Scan() {
+		line := strings.TrimSpace(rs.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			return true
+		}
+		for _, f := range strings.FieldsFunc(line, func(r rune) bool { return r == '|' || r == ',' }) {
+			if _, err := parseTime(strings.TrimSpace(f)); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
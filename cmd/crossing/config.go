@@ -17,6 +17,10 @@ type Area struct {
 	Lat    float64 `toml:"latitude"`
 	Lng    float64 `toml:"longitude"`
 	Margin float64
+	Radius float64
+	File   string
+	AltMin float64 `toml:"altmin"`
+	AltMax float64 `toml:"altmax"`
 
 	Night bool
 
@@ -25,7 +29,18 @@ type Area struct {
 }
 
 func (a Area) Accept() (Accepter, error) {
-	sq, err := NewSquare(a.Lat, a.Lng, a.Margin)
+	var (
+		sq  Accepter
+		err error
+	)
+	switch {
+	case a.File != "":
+		sq, err = NewPolygon(a.File)
+	case a.Radius > 0:
+		sq, err = NewCircle(a.Lat, a.Lng, a.Radius)
+	default:
+		sq, err = NewSquare(a.Lat, a.Lng, a.Margin)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -33,13 +48,18 @@ func (a Area) Accept() (Accepter, error) {
 		Starts: a.Starts,
 		Ends:   a.Ends,
 	}
-	return NewFilter(a.Label, sq, pd, Eclipse(a.Night)), nil
+	alt := Altitude{
+		Min: a.AltMin,
+		Max: a.AltMax,
+	}
+	return NewFilter(a.Label, sq, alt, pd, Eclipse(a.Night)), nil
 }
 
 type Setting struct {
-	File  string
-	List  bool
-	Comma bool `toml:"csv"`
+	File    string
+	List    bool
+	Comma   bool `toml:"csv"`
+	GeoJSON bool `toml:"geojson"`
 
 	Areas []Area `toml:"area"`
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"github.com/busoc/inspect/shape"
 )
 
 type Accepter interface {
@@ -103,6 +105,65 @@ func (s Square) String() string {
 	return fmt.Sprintf("crossing area: [%.3fS,%.3fN]x[%.3fW,%.3fE]", s.South, s.North, s.West, s.East)
 }
 
+type Polygon struct {
+	shape.MultiPolygon
+	File string
+}
+
+func NewPolygon(file string) (Polygon, error) {
+	mp, err := shape.Load(file)
+	if err != nil {
+		return Polygon{}, err
+	}
+	return Polygon{MultiPolygon: mp, File: file}, nil
+}
+
+func (p Polygon) Accept(pt Point) (bool, string) {
+	return p.Contains(pt.Lat, pt.Lng), ""
+}
+
+func (p Polygon) String() string {
+	return fmt.Sprintf("crossing area: %d polygon(s) from %s", len(p.MultiPolygon), p.File)
+}
+
+type Circle struct {
+	shape.Circle
+}
+
+func NewCircle(lat, lng, radius float64) (Circle, error) {
+	c := Circle{
+		Circle: shape.Circle{Lat: lat, Lon: lng, Radius: radius},
+	}
+	if radius <= 0 {
+		return c, fmt.Errorf("invalid radius")
+	}
+	return c, nil
+}
+
+func (c Circle) Accept(pt Point) (bool, string) {
+	return c.Contains(pt.Lat, pt.Lng), ""
+}
+
+func (c Circle) String() string {
+	return fmt.Sprintf("crossing area: %.1fkm around [%.3f,%.3f]", c.Radius, c.Lat, c.Lon)
+}
+
+type Altitude struct {
+	Min float64
+	Max float64
+}
+
+func (a Altitude) Accept(pt Point) (bool, string) {
+	return shape.Range(a).Contains(pt.Alt), ""
+}
+
+func (a Altitude) String() string {
+	if a.Max <= 0 {
+		return fmt.Sprintf("crossing altitude: [%.1fkm,]", a.Min)
+	}
+	return fmt.Sprintf("crossing altitude: [%.1fkm,%.1fkm]", a.Min, a.Max)
+}
+
 type Eclipse bool
 
 func (e Eclipse) Accept(pt Point) (bool, string) {
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/busoc/inspect/shape"
+)
+
+type feature struct {
+	Type       string                 `json:"type"`
+	Geometry   geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geometry struct {
+	Type   string      `json:"type"`
+	Coords interface{} `json:"coordinates"`
+}
+
+// track gives the points of the path as lines split at the antimeridian.
+func track(p Path) [][][]float64 {
+	var (
+		ls   [][][]float64
+		cs   [][]float64
+		prev *Point
+	)
+	for i, pt := range p.Points {
+		if prev != nil {
+			if lat, edge, ok := shape.Antimeridian(prev.Lat, prev.Lng, pt.Lat, pt.Lng); ok {
+				ls = append(ls, append(cs, []float64{edge, lat}))
+				cs = [][]float64{{-edge, lat}}
+			}
+		}
+		cs = append(cs, []float64{pt.Lng, pt.Lat})
+		prev = &p.Points[i]
+	}
+	return append(ls, cs)
+}
+
+// printGeoJSON writes the paths as a GeoJSON FeatureCollection of LineString
+// following the trajectory from the entry to the exit of the area. A path
+// crossing the antimeridian is given as a MultiLineString split at ±180°.
+func printGeoJSON(w io.Writer, paths []Path) error {
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i].Less(paths[j])
+	})
+	fs := make([]feature, 0, len(paths))
+	for _, p := range paths {
+		g := geometry{Type: "MultiLineString"}
+		if ls := track(p); len(ls) == 1 {
+			g.Type, g.Coords = "LineString", ls[0]
+		} else {
+			g.Coords = ls
+		}
+		f := feature{
+			Type:     "Feature",
+			Geometry: g,
+			Properties: map[string]interface{}{
+				"label":    p.Label,
+				"starts":   p.First.When.Format(time.RFC3339Nano),
+				"ends":     p.Last.When.Format(time.RFC3339Nano),
+				"duration": p.Delta().Seconds(),
+				"distance": p.Distance(),
+			},
+		}
+		fs = append(fs, f)
+	}
+	c := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{
+		Type:     "FeatureCollection",
+		Features: fs,
+	}
+	return json.NewEncoder(w).Encode(c)
+}
@@ -24,6 +24,10 @@ file. When this option is choosen, the file given as argument is considered to
 be one valid configuration file. When not used, crosspath considers its arguments
 as one to multiple trajectory files.
 
+the trajectory files are the csv output of inspect, including the combined output
+of inspect -catalog: the columns are located from the header of the file (or
+from the first row when the header is missing).
+
 the main advantage of using a configuration with crosspath is the ability to
 specify multiple areas of interest.
 
@@ -51,14 +55,19 @@ options:
   margin  MARGIN  size of the area of interest around the center
   lat     LAT     latitude used as the center of the area of interest
   lng     LNG     longitude used as the center of the area of interest
+  radius  RADIUS  distance (km) around the center, replaces margin when given
+  area    FILE    GeoJSON or WKT file with the polygon(s) of the area of interest
+  altmin  ALT     only take crossing of area above ALT km
+  altmax  ALT     only take crossing of area below ALT km (0: no limit)
   night           only take crossing of area occuring during an eclipse
   csv             output crossing as comma separated value
+  geojson         output crossing as GeoJSON lines following the trajectory
   config          use a configuration file to specify the area(s) of interest
   version         print the version of crosspath and exit
   help            print this help message and exit
 
 usages:
-$ crosspath [-starts] [-ends] [-margin] [-label] [-lat] [-lng] [-night] [-csv] <trajectory...>
+$ crosspath [-starts] [-ends] [-margin] [-label] [-lat] [-lng] [-radius] [-area] [-altmin] [-altmax] [-night] [-csv] [-geojson] <trajectory...>
 $ crosspath -config <config.toml>
 $ crosspath -version
 $ crosspath -help
@@ -71,15 +80,20 @@ func main() {
 	}
 	var (
 		comma  = flag.Bool("csv", false, "csv")
+		geo    = flag.Bool("geojson", false, "geojson")
 		// list   = flag.Bool("list", false, "list")
 		lat    = flag.Float64("lat", 0, "latitude")
 		lng    = flag.Float64("lng", 0, "longitude")
 		mgn    = flag.Float64("margin", 10, "margin")
+		radius = flag.Float64("radius", 0, "radius")
+		altmin = flag.Float64("altmin", 0, "minimum altitude")
+		altmax = flag.Float64("altmax", 0, "maximum altitude")
 		night  = flag.Bool("night", false, "night")
 		starts = flag.String("starts", "", "start time")
 		ends   = flag.String("ends", "", "end time")
 		config = flag.Bool("config", false, "use config file")
 		label  = flag.String("label", "", "label")
+		file   = flag.String("area", "", "polygon file")
 		version = flag.Bool("version", false, "version")
 		help    = flag.Bool("help", false, "help")
 	)
@@ -110,11 +124,19 @@ func main() {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(2)
 		}
-		*comma = s.Comma
+		*comma, *geo = s.Comma, s.GeoJSON
 
 		paths, err = s.Paths()
 	} else {
-		sq, err := NewSquare(*lat, *lng, *mgn)
+		var sq Accepter
+		switch {
+		case *file != "":
+			sq, err = NewPolygon(*file)
+		case *radius > 0:
+			sq, err = NewCircle(*lat, *lng, *radius)
+		default:
+			sq, err = NewSquare(*lat, *lng, *mgn)
+		}
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(2)
@@ -125,18 +147,28 @@ func main() {
 			os.Exit(2)
 		}
 		ec := Eclipse(*night)
+		alt := Altitude{Min: *altmin, Max: *altmax}
 
 		fmt.Fprintln(os.Stderr, pd.String())
-		fmt.Fprintln(os.Stderr, sq.String())
+		fmt.Fprintln(os.Stderr, sq)
+		fmt.Fprintln(os.Stderr, alt.String())
 		fmt.Fprintln(os.Stderr, ec.String())
 
-		paths, err = ReadPaths(flag.Args(), NewFilter(*label, sq, pd, ec))
+		paths, err = ReadPaths(flag.Args(), NewFilter(*label, sq, alt, pd, ec))
 	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
-	printPaths(Line(*comma), paths)
+	if *geo {
+		err = printGeoJSON(os.Stdout, paths)
+	} else {
+		err = printPaths(Line(*comma), paths)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
 func printPaths(ws *linewriter.Writer, paths []Path) error {
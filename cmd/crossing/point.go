@@ -1,11 +1,12 @@
 package main
 
 import (
-	"encoding/csv"
+	"bufio"
 	"io"
 	"math"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -43,27 +44,50 @@ func ReadPoints(files []string) (<-chan Point, error) {
 				}
 			}
 		}()
+		scanPoints(r, func(pt Point) { queue <- pt })
+	}()
+	return queue, nil
+}
 
-		rs := csv.NewReader(r)
-		rs.Comment = '#'
-		rs.Comma = ','
-		rs.FieldsPerRecord = 8
-		for {
-			row, err := rs.Read()
-			if err != nil {
-				break
+// scanPoints reads the rows of the csv output of inspect from r and gives each
+// point read to fn. The columns are located from the header of the output
+// (#time, mjd, altitude...) that starts with a sid column in catalog mode. They
+// are located from the first row when the header is missing.
+func scanPoints(r io.Reader, fn func(Point)) {
+	var (
+		cs    columns
+		found bool
+	)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if c, ok := parseColumns(line[1:]); ok {
+				cs, found = c, true
 			}
-
-			queue <- FromRow(row)
+			continue
 		}
-	}()
-	return queue, nil
+		row := strings.Split(line, ",")
+		if !found {
+			cs, found = guessColumns(row), true
+		}
+		if len(row) <= cs.max() {
+			continue
+		}
+		fn(cs.point(row))
+	}
 }
 
 type Path struct {
 	Label string
 	First Point
 	Last  Point
+
+	// Points of the trajectory from the first to the last point of the path
+	Points []Point
 }
 
 func (p Path) Distance() float64 {
@@ -91,16 +115,19 @@ func ReadPaths(files []string, accept Accepter) ([]Path, error) {
 	for pt := range queue {
 		if ok, label := accept.Accept(pt); ok {
 			first, last = pt, pt
+			points := []Point{pt}
 			for pt := range queue {
 				if ok, _ := accept.Accept(pt); !ok {
 					break
 				}
 				last = pt
+				points = append(points, pt)
 			}
 			p := Path{
-				Label: label,
-				First: first,
-				Last: last,
+				Label:  label,
+				First:  first,
+				Last:   last,
+				Points: points,
 			}
 			paths = append(paths, p)
 		}
@@ -117,19 +144,95 @@ type Point struct {
 	Saa     bool
 }
 
-func FromRow(row []string) Point {
+// columns gives the indices of the columns of a row read by crosspath.
+type columns struct {
+	When    int
+	Alt     int
+	Lat     int
+	Lng     int
+	Eclipse int
+	Saa     int
+}
+
+// defaultColumns are the columns of the csv output of inspect for one
+// satellite.
+var defaultColumns = columns{When: 0, Alt: 2, Lat: 3, Lng: 4, Eclipse: 5, Saa: 6}
+
+// parseColumns locates the columns from the names of the header of the csv
+// output of inspect (without its leading #).
+func parseColumns(header string) (columns, bool) {
+	ix := make(map[string]int)
+	for i, h := range strings.Split(header, ",") {
+		ix[strings.TrimSpace(h)] = i
+	}
+	var cs columns
+	for _, c := range []struct {
+		v   *int
+		key string
+	}{
+		{&cs.When, "time"},
+		{&cs.Alt, "altitude"},
+		{&cs.Lat, "latitude"},
+		{&cs.Lng, "longitude"},
+		{&cs.Eclipse, "eclipse"},
+		{&cs.Saa, "saa"},
+	} {
+		i, ok := ix[c.key]
+		if !ok {
+			return cs, false
+		}
+		*c.v = i
+	}
+	return cs, true
+}
+
+// guessColumns gives the columns of row when the header is missing: they are
+// shifted by the sid column of the catalog mode when the time is found in the
+// second column.
+func guessColumns(row []string) columns {
+	cs := defaultColumns
+	if len(row) > 1 && !isTime(row[0]) && isTime(row[1]) {
+		cs.When, cs.Alt, cs.Lat, cs.Lng, cs.Eclipse, cs.Saa = 1, 3, 4, 5, 6, 7
+	}
+	return cs
+}
+
+func (c columns) max() int {
+	m := c.When
+	for _, v := range []int{c.Alt, c.Lat, c.Lng, c.Eclipse, c.Saa} {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func (c columns) point(row []string) Point {
 	var pt Point
 
-	pt.When, _ = time.Parse("2006-01-02T15:04:05.000000", row[0])
-	pt.Alt, _ = strconv.ParseFloat(row[2], 64)
-	pt.Lat, _ = strconv.ParseFloat(row[3], 64)
-	pt.Lng, _ = strconv.ParseFloat(row[4], 64)
-	pt.Eclipse, _ = strconv.ParseBool(row[5])
-	pt.Saa, _ = strconv.ParseBool(row[6])
+	pt.When, _ = time.Parse(timeFormat, row[c.When])
+	pt.Alt, _ = strconv.ParseFloat(row[c.Alt], 64)
+	pt.Lat, _ = strconv.ParseFloat(row[c.Lat], 64)
+	pt.Lng, _ = strconv.ParseFloat(row[c.Lng], 64)
+	pt.Eclipse, _ = strconv.ParseBool(row[c.Eclipse])
+	pt.Saa, _ = strconv.ParseBool(row[c.Saa])
 
 	return pt
 }
 
+const timeFormat = "2006-01-02T15:04:05.000000"
+
+func isTime(str string) bool {
+	_, err := time.Parse(timeFormat, str)
+	return err == nil
+}
+
+// FromRow gives the point of a row of the csv output of inspect for one
+// satellite.
+func FromRow(row []string) Point {
+	return defaultColumns.point(row)
+}
+
 func (p Point) Distance(t Point) float64 {
 	x0, y0, z0 := p.Coordinates()
 	x1, y1, z1 := t.Coordinates()
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func Example_scanPoints() {
+	const catalog = `#sid, time, mjd, altitude, latitude, longitude, eclipse, saa, epoch, vx, vy, vz, ground, speed
+#1 25544U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9995
+#2 25544  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139693
+25544,2018-10-31T08:37:20.838144,25140.359268960077,398.43559311163705,41.196078202033036,-64.92277509346772,1,0,2458422.85926896,4.183677218377214,5.114033465864418,3.2617784082270114,6.935318314779606,7.672277476976368
+25544,2018-10-31T08:38:20.838143,25140.35996340448,398.28741262004434,43.31358430363847,-60.75995632205415,1,0,2458422.85926896,4.055176405697005,5.4188512489218805,2.9111258032924456,6.934663233096192,7.67203980213118
+`
+	for _, str := range []string{catalog, catalog[strings.Index(catalog, "\n")+1:]} {
+		scanPoints(strings.NewReader(str), func(pt Point) {
+			fmt.Printf("%s: %.3f %.3f %.3f %t %t", pt.When.Format("15:04:05"), pt.Lat, pt.Lng, pt.Alt, pt.Eclipse, pt.Saa)
+			fmt.Println()
+		})
+	}
+	// Output:
+	// 08:37:20: 41.196 -64.923 398.436 true false
+	// 08:38:20: 43.314 -60.760 398.287 true false
+	// 08:37:20: 41.196 -64.923 398.436 true false
+	// 08:38:20: 43.314 -60.760 398.287 true false
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/busoc/inspect"
+)
+
+var extensions = map[string]string{
+	"":        ".txt",
+	"pipe":    ".txt",
+	"csv":     ".csv",
+	"json":    ".json",
+	"ndjson":  ".ndjson",
+	"xml":     ".xml",
+	"oem":     ".oem",
+	"oem-xml": ".xml",
+	"geojson": ".geojson",
+	"kml":     ".kml",
+	"kmz":     ".kmz",
+}
+
+func fetchCatalog(ps []string, s *Settings) (*celest.Catalog, error) {
+	var c celest.Catalog
+	if s.Lenient {
+		c.Mode = celest.Lenient
+	}
+	err := fetch(ps, s.Temp, func(r io.Reader, format string) error {
+		return c.ScanFormat(r, format, s.BStar)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// runCatalog predicts the trajectory of every satellite found in the sources
+// with a pool of workers. The outputs are written in one file per satellite
+// in s.Dir or combined (in the order of the sources) with a column giving the
+// satellite number.
+func runCatalog(sources []string, s Settings, base time.Time, delay bool) error {
+	c, err := fetchCatalog(sources, &s)
+	if err != nil {
+		return err
+	}
+	ts := c.Trajectories()
+	log.Printf("%d satellites found (%d elements skipped)", len(ts), c.Skipped)
+	if s.TLEOut != "" {
+		if err := writeTLE(s.TLEOut, ts...); err != nil {
+			return err
+		}
+	}
+
+	format := strings.ToLower(s.Print.Format)
+	if s.Dir != "" {
+		if err := os.MkdirAll(s.Dir, 0755); err != nil {
+			return err
+		}
+	} else if !s.Passes && !s.Events && !s.Eclipses {
+		switch format {
+		case "", "pipe", "csv":
+			s.Print.catalog = true
+		default:
+			return badUsage(fmt.Sprintf("format %s can not be combined for a catalog (use -dir)", s.Print.Format))
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if s.Dir == "" && s.File != "" {
+		f, err := os.Create(s.File)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if s.Dir == "" && s.Print.catalog && format == "csv" {
+		s.Print.printHeader(w, s)
+	}
+
+	type result struct {
+		index int
+		out   *bytes.Buffer
+		err   error
+	}
+	var (
+		queue   = make(chan int)
+		results = make(chan result)
+		wg      sync.WaitGroup
+	)
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				var out bytes.Buffer
+				err := runSatellite(&out, ts[j], s, base, delay)
+				results <- result{index: j, out: &out, err: err}
+			}
+		}()
+	}
+	go func() {
+		for i := range ts {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+		close(results)
+	}()
+
+	// the output of a satellite is written (and released) as soon as the ones
+	// of all the satellites before it are written.
+	var (
+		pending = make(map[int]result)
+		next    int
+	)
+	for r := range results {
+		pending[r.index] = r
+		for x, ok := pending[next]; ok; x, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if x.err != nil {
+				log.Printf("%s: %s", celest.FormatCatalog(ts[x.index].Sid()), x.err)
+			}
+			if err == nil && s.Dir == "" {
+				_, err = x.out.WriteTo(w)
+			}
+		}
+	}
+	return err
+}
+
+// runSatellite predicts the trajectory (or the passes, crossings or eclipses)
+// of one satellite and writes it to w or to its own file when s.Dir is set.
+func runSatellite(w io.Writer, t *celest.Trajectory, s Settings, base time.Time, delay bool) error {
+	t.Base = base
+	s.sat = satellite{Sid: t.Sid(), Name: t.Name()}
+	sid := celest.FormatCatalog(s.sat.Sid)
+
+	if s.Dir != "" {
+		ext := extensions[strings.ToLower(s.Print.Format)]
+		if s.Passes || s.Events || s.Eclipses {
+			ext = ".txt"
+			if strings.ToLower(s.Print.Format) == "csv" {
+				ext = ".csv"
+			}
+		}
+		f, err := os.Create(filepath.Join(s.Dir, sid+ext))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	switch {
+	case s.Passes:
+		n, err := printPasses(w, t, s, delay)
+		if err == nil {
+			log.Printf("%s: %d passes over %s", sid, n, s.Station.String())
+		}
+		return err
+	case s.Events:
+		n, err := printEvents(w, t, s, delay)
+		if err == nil {
+			log.Printf("%s: %d crossing during trajectory", sid, n)
+		}
+		return err
+	case s.Eclipses:
+		n, err := printEclipses(w, t, s, delay)
+		if err == nil {
+			log.Printf("%s: %d eclipses during trajectory", sid, n)
+		}
+		return err
+	}
+	if strings.ToLower(s.Print.Format) == "geojson" {
+		var err error
+		if s.Print.events, err = s.Print.geoEvents(t, s, delay); err != nil {
+			return err
+		}
+	}
+	rs, err := t.Predict(s.Period.Duration, s.Interval.Duration, &s.Area, delay, s.Areas.Areas()...)
+	if err != nil {
+		return err
+	}
+	m, err := s.Print.Print(w, rs, s)
+	if err != nil {
+		return err
+	}
+	log.Printf("%s: %d TLE used, %d positions, %d eclipses, %d crossing", sid, m.TLE, m.Points, m.Eclipse, m.Crossing)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/busoc/inspect"
+	"github.com/midbel/linewriter"
+)
+
+// fetchPair gives the trajectories of the satellites selected with -s and
+// -with. With two sources, the first satellite is read from the first source
+// and the second satellite from the second source (the first satellite found
+// when -with is not given).
+func fetchPair(ps []string, s *Settings) (*celest.Trajectory, *celest.Trajectory, error) {
+	first, second := ps, ps
+	switch {
+	case len(ps) == 2:
+		first, second = ps[:1], ps[1:]
+	case s.with.IsZero():
+		return nil, nil, badUsage("conjunction: second satellite not given (-with or second source)")
+	}
+	t, err := fetchSatellite(first, s, &s.sat)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := fetchSatellite(second, s, &s.with)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(t.Elements()) == 0 {
+		return nil, nil, fmt.Errorf("conjunction: no elements found for satellite %s", s.sat.String())
+	}
+	if len(o.Elements()) == 0 {
+		return nil, nil, fmt.Errorf("conjunction: no elements found for satellite %s", s.with.String())
+	}
+	return t, o, nil
+}
+
+func printConjunctions(w io.Writer, t, o *celest.Trajectory, s Settings, delay bool) (int, error) {
+	const tfmt = "2006-01-02T15:04:05.000"
+
+	cs, err := t.Conjunctions(o, s.Period.Duration, s.Interval.Duration, delay, s.Miss)
+	if err != nil {
+		return 0, err
+	}
+	ws := newLine(s.Print.Format)
+	for _, c := range cs {
+		ws.AppendString(celest.FormatCatalog(c.Sid), 6, linewriter.AlignRight)
+		ws.AppendString(celest.FormatCatalog(c.Other), 6, linewriter.AlignRight)
+		ws.AppendTime(s.Print.when(c.TCA), tfmt, linewriter.AlignLeft)
+		ws.AppendFloat(c.Distance, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(c.Radial, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(c.InTrack, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(c.CrossTrack, 10, 3, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(c.Speed, 7, 3, linewriter.AlignRight|linewriter.Float)
+
+		if _, err := io.Copy(w, ws); err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+	return len(cs), nil
+}
@@ -74,9 +74,9 @@ func checkError(err, parent error) error {
 	switch e := err.(type) {
 	case *Error:
 		return e
-	case *celest.ParseError:
+	case *celest.ParseError, celest.ChecksumError, *celest.OMMError:
 		return &Error{
-			Cause: e,
+			Cause: err,
 			Code:  TLEDataErrCode,
 		}
 	case celest.PropagationError:
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect"
+	"github.com/midbel/linewriter"
+)
+
+func printEvents(w io.Writer, t *celest.Trajectory, s Settings, delay bool) (int, error) {
+	const tfmt = "2006-01-02T15:04:05.000"
+
+	es, err := t.Crossings(s.Period.Duration, s.Interval.Duration, &s.Area, delay, s.Areas.Areas()...)
+	if err != nil {
+		return 0, err
+	}
+	ws := newLine(s.Print.Format)
+	for _, e := range es {
+		ws.AppendString(e.Label, 12, linewriter.AlignLeft)
+		ws.AppendString(celest.FormatCatalog(e.Sid), 6, linewriter.AlignRight)
+		ws.AppendTime(s.Print.when(e.Starts), tfmt, linewriter.AlignLeft)
+		ws.AppendTime(s.Print.when(e.Ends), tfmt, linewriter.AlignLeft)
+		ws.AppendDuration(e.Duration().Truncate(time.Millisecond), 10, linewriter.AlignRight|linewriter.Millisecond)
+
+		if _, err := io.Copy(w, ws); err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+	return len(es), nil
+}
+
+func printEclipses(w io.Writer, t *celest.Trajectory, s Settings, delay bool) (int, error) {
+	const tfmt = "2006-01-02T15:04:05.000"
+
+	es, err := t.Eclipses(s.Period.Duration, s.Interval.Duration, delay)
+	if err != nil {
+		return 0, err
+	}
+	ws := newLine(s.Print.Format)
+	for _, e := range es {
+		ws.AppendString(celest.FormatCatalog(e.Sid), 6, linewriter.AlignRight)
+		for _, t := range []time.Time{e.PenumbraStarts, e.UmbraStarts, e.UmbraEnds, e.PenumbraEnds} {
+			if t.IsZero() {
+				ws.AppendString("-", len(tfmt), linewriter.AlignLeft)
+			} else {
+				ws.AppendTime(s.Print.when(t), tfmt, linewriter.AlignLeft)
+			}
+		}
+		ws.AppendDuration(e.Duration().Truncate(time.Millisecond), 10, linewriter.AlignRight|linewriter.Millisecond)
+		ws.AppendDuration(e.Penumbra().Truncate(time.Millisecond), 10, linewriter.AlignRight|linewriter.Millisecond)
+		ws.AppendFloat(e.Occulted, 6, 2, linewriter.AlignRight|linewriter.Float)
+
+		if _, err := io.Copy(w, ws); err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+	return len(es), nil
+}
+
+func newLine(format string) *linewriter.Writer {
+	var opts []linewriter.Option
+	if strings.ToLower(format) == "csv" {
+		opts = append(opts, linewriter.AsCSV(true))
+	} else {
+		opts = []linewriter.Option{
+			linewriter.WithPadding([]byte(" ")),
+			linewriter.WithSeparator([]byte("|")),
+		}
+	}
+	return linewriter.NewWriter(8192, opts...)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+
+	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/shape"
+)
+
+type geoCollection struct {
+	Type     string       `json:"type"`
+	Features []geoFeature `json:"features"`
+}
+
+type geoFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoGeometry            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoGeometry struct {
+	Type   string      `json:"type"`
+	Coords interface{} `json:"coordinates"`
+}
+
+func geoPoint(p *celest.Point, props map[string]interface{}) geoFeature {
+	return geoFeature{
+		Type:       "Feature",
+		Geometry:   geoGeometry{Type: "Point", Coords: []float64{p.Lon, p.Lat}},
+		Properties: props,
+	}
+}
+
+// geoTrack gives the ground track of the points as LineString features split
+// at the antimeridian.
+func geoTrack(r *celest.Result, ps []*celest.Point) []geoFeature {
+	var (
+		fs    []geoFeature
+		cs    [][]float64
+		first *celest.Point
+		prev  *celest.Point
+	)
+	flush := func(last *celest.Point) {
+		if len(cs) < 2 {
+			return
+		}
+		props := map[string]interface{}{
+			"sid":    celest.FormatCatalog(r.Sid),
+			"name":   r.Name,
+			"tle":    r.TLE,
+			"epoch":  r.When.Format(time.RFC3339),
+			"starts": first.When.Format(time.RFC3339Nano),
+			"ends":   last.When.Format(time.RFC3339Nano),
+		}
+		f := geoFeature{
+			Type:       "Feature",
+			Geometry:   geoGeometry{Type: "LineString", Coords: cs},
+			Properties: props,
+		}
+		fs = append(fs, f)
+	}
+	for _, p := range ps {
+		if prev == nil {
+			first = p
+		} else if lat, edge, ok := shape.Antimeridian(prev.Lat, prev.Lon, p.Lat, p.Lon); ok {
+			cs = append(cs, []float64{edge, lat})
+			flush(prev)
+			cs, first = [][]float64{{-edge, lat}}, p
+		}
+		cs = append(cs, []float64{p.Lon, p.Lat})
+		prev = p
+	}
+	if prev != nil {
+		flush(prev)
+	}
+	return fs
+}
+
+// geoEvents gives the points where the satellite enters and exits the crossing
+// areas, the penumbra (penumbra-entry/exit) and the umbra (eclipse-entry/exit).
+// The times are refined between two points of the trajectory like with -events
+// and -eclipses.
+func (pt printer) geoEvents(t *celest.Trajectory, s Settings, delay bool) ([]geoFeature, error) {
+	es, err := t.Crossings(s.Period.Duration, s.Interval.Duration, &s.Area, delay, s.Areas.Areas()...)
+	if err != nil {
+		return nil, err
+	}
+	xs, err := t.Eclipses(s.Period.Duration, s.Interval.Duration, delay)
+	if err != nil {
+		return nil, err
+	}
+	type event struct {
+		Label string
+		When  time.Time
+	}
+	var vs []event
+	for _, e := range es {
+		vs = append(vs, event{e.Label + "-entry", e.Starts}, event{e.Label + "-exit", e.Ends})
+	}
+	for _, e := range xs {
+		if !e.PenumbraStarts.IsZero() {
+			vs = append(vs, event{"penumbra-entry", e.PenumbraStarts}, event{"penumbra-exit", e.PenumbraEnds})
+		}
+		if !e.UmbraStarts.IsZero() {
+			vs = append(vs, event{"eclipse-entry", e.UmbraStarts}, event{"eclipse-exit", e.UmbraEnds})
+		}
+	}
+	syst := pt.geoSyst()
+	fs := make([]geoFeature, 0, len(vs))
+	for _, v := range vs {
+		p, err := t.At(v.When)
+		if err != nil {
+			return nil, err
+		}
+		p = pt.retime(transform(p, syst))
+		fs = append(fs, geoPoint(p, geoProps(t, v.Label, p)))
+	}
+	return fs, nil
+}
+
+func geoProps(t *celest.Trajectory, event string, p *celest.Point) map[string]interface{} {
+	return map[string]interface{}{
+		"sid":   celest.FormatCatalog(t.Sid()),
+		"name":  t.Name(),
+		"event": event,
+		"when":  p.When.Format(time.RFC3339Nano),
+		"alt":   p.Alt,
+	}
+}
+
+// geoSyst gives the system of the ground track: geodetic when the output is
+// in a cartesian frame.
+func (pt printer) geoSyst() string {
+	if pt.rawFormat() {
+		return "geodetic"
+	}
+	return pt.Syst
+}
+
+// printGeoJSON writes the ground track of the predicted trajectory as a
+// GeoJSON FeatureCollection with one LineString per TLE used (split at the
+// antimeridian) followed by the points of the events computed beforehand.
+func (pt printer) printGeoJSON(w io.Writer, ps <-chan *celest.Result) (*meta, error) {
+	var m meta
+	syst := pt.geoSyst()
+	c := geoCollection{Type: "FeatureCollection", Features: []geoFeature{}}
+	for r := range ps {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
+		m.TLE++
+		m.Points += len(r.Points)
+
+		xs := make([]*celest.Point, len(r.Points))
+		for i, p := range r.Points {
+			xs[i] = pt.retime(transform(p, syst))
+			m.count(xs[i])
+			m.cross(pt.labels, xs[i].Areas)
+		}
+		c.Features = append(c.Features, geoTrack(r, xs)...)
+	}
+	c.Features = append(c.Features, pt.events...)
+	return &m, json.NewEncoder(w).Encode(c)
+}
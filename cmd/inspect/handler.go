@@ -28,7 +28,7 @@ func Handle(s Settings) http.Handler {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		rs, err := e.Predict(n.Period.Duration, n.Interval.Duration, &n.Area)
+		rs, err := e.Predict(n.Period.Duration, n.Interval.Duration, &n.Area, n.Areas.Areas()...)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -49,6 +49,7 @@ func Handle(s Settings) http.Handler {
 			}
 			err = xml.NewEncoder(&buffer).Encode(rs.Points)
 		case "text/csv":
+			n.Print.labels = n.Areas.Labels()
 			err = n.Print.printRow(csv.NewWriter(&buffer), rs, nil)
 		default:
 			w.WriteHeader(http.StatusNotAcceptable)
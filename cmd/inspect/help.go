@@ -28,15 +28,69 @@ inertial system that do not rotate with the earth. These values are the outcome
 of the SGP4 propagator used by inspect and are used the computed the latitude,
 longitude in the geodetic or geocentric frame.
 
+- j2000/eme2000, gcrf: the position and velocity are transformed from the TEME
+frame to the mean equator and equinox of J2000 (IAU-76 precession and IAU-80
+nutation) or to the GCRF (the same model corrected by the celestial pole
+offsets DPSI and DEPS of the EOP file in CSV given with -eop). The finals2000A
+files do not give these offsets: without them, gcrf is the same as j2000 and
+inspect logs it. As with teme, the altitude, latitude and longitude columns
+give z, x and y.
+
+the positions in the earth fixed systems are all transformed from the TEME frame
+with the same rotation: the IAU-82 Greenwich mean sidereal time (with the
+kinematic terms of the equation of the equinoxes) then the polar motion.
+
+the earth orientation parameters (polar motion, UT1-UTC, length of day and
+celestial pole offsets) are read with -eop from an IERS finals2000A file or from
+an EOP file in CSV of CelesTrak (eg: EOP-All.csv), interpolated between two
+days. Only the CSV files give the celestial pole offsets. Without -eop, a
+snapshot of the EOP file of CelesTrak embedded in inspect is used (refreshed
+with go generate ./eop). Outside of the dates of the snapshot, the polar motion
+is ignored and UT1 is UTC: the longitudes and the positions in the earth fixed
+frame can then be off by up to 0.9s of earth rotation (about 400m at the
+equator). The leap seconds (TAI-UTC) are given by the CSV file of CelesTrak or
+by a table embedded in inspect.
+
 TLE/Input format:
 
-inspect can only support the following TLE format (the first line being optional.
-But if present should be 24 characters long)
+inspect can only support the following TLE format (the first line, giving the
+name of the satellite, being optional)
 
 ISS (ZARYA)
 1 25544U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9995
 2 25544  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139693
 
+inspect also supports the CCSDS Orbit Mean-elements Message (OMM) as published
+by CelesTrak and Space-Track in XML, JSON, CSV and KVN. The format of the input
+is detected from its content (or from the content type of the response for a
+remote file). The satellite is selected by its NORAD_CAT_ID. Only the messages
+in the TEME frame (REF_FRAME) and in UTC (TIME_SYSTEM) are accepted.
+
+catalog numbers above 99999 are given in the Alpha-5 scheme in a TLE (the first
+digit is replaced by a letter, I and O excluded: A0001 is 100001). They can be
+given in both forms with -s. In the configuration file, the satellite key gives
+the number in decimal and the satellite-name key a name or an Alpha-5 number
+(with and with-name for the second satellite of -conjunction). A name takes
+precedence over a number.
+
+the satellite can also be selected by its name as given in the title line of a
+TLE (or the OBJECT_NAME of an OMM): -s "ISS (ZARYA)". The name matches when it
+is equal to, contained in or matches (as a regular expression) the name of the
+satellite ignoring case. The first satellite matching is used and its name is
+given in the headers of the output and by -info.
+
+the rows of a TLE are read by columns. By default, each row should be 69
+characters long with a valid checksum (last column) and both rows should have
+the same satellite number. With -lenient (or lenient in the configuration file),
+these checks are not done and the trailing spaces of the rows can be missing.
+
+with -catalog, inspect predicts the trajectory of every satellite found in the
+input (eg: a group file of CelesTrak) with a pool of -workers satellites
+predicted concurrently. Invalid elements are skipped. The outputs are combined
+with a first column giving the satellite number (only with the csv and pipe
+formats for the trajectory) or written in one file per satellite (named after
+its number) in the directory given with -dir.
+
 Output format:
 
 
@@ -50,19 +104,165 @@ the output of inspect consists of a tabulated "file". The columns of the file ar
 - eclipse (1: night, 0: day)
 - crossing (1: crossing, 0: no crossing)
 - TLE epoch (not printed when output is pipe separated)
+- velocity x, y, z (kilometer/second, in the frame of the position)
+- ground speed (kilometer/second)
+- inertial speed (kilometer/second)
+- with -sun: beta angle (degree) and unit vector from the satellite to the sun
+- with -moon: unit vector from the satellite to the moon and angle between the
+  moon and the limb of the earth (degree, negative when hidden by the earth)
+- one crossing column per named area (1: crossing, 0: no crossing)
+
+the unit vectors are given in the frame of the position (earth fixed unless the
+position is given in an inertial frame). The beta angle is the angle between the
+orbital plane and the direction of the sun. The positions of the sun and of the
+moon come from the truncated VSOP87 and ELP-2000/82 theories (J. Meeus,
+Astronomical Algorithms) and the sun is also used for the eclipses. With -f
+json, ndjson or xml, they are given in the sun (beta, x, y, z) and moon (x, y,
+z, limb) fields of each point. The other formats (oem, kml, geojson) can not
+carry them and are rejected with -sun or -moon.
+
+with -f json or -f xml, the output is one document with the settings of inspect
+and, for each TLE used, its lines, its epoch and the points predicted from it.
+With -f ndjson, each point is written as one JSON object per line with the
+identifier and the name of the satellite and the TLE it is predicted from. With
+-timescale, the time of each point is given without the Z suffix of UTC and
+with the name of its time scale.
+
+with -f oem or -f oem-xml, the output is a CCSDS Orbit Ephemeris Message (KVN or
+XML) with one segment per TLE used. The reference frame is TEME with -c teme/eci,
+EME2000 with -c j2000, GCRF with -c gcrf and ITRF otherwise. Each line of the
+ephemeris gives the position (kilometer) and the velocity (kilometer/second) of
+the satellite.
+
+with -f kml or -f kmz, the output is a KML document (zipped with kmz) to be
+opened in Google Earth. It contains the ground track of the satellite split at
+the antimeridian with the eclipses and the crossings drawn in their own style,
+the crossing area(s) and a time-stamped track of the satellite for animation.
+
+with -f geojson, the output is a GeoJSON FeatureCollection with the ground track
+of each TLE used as LineString(s) split at the antimeridian and one Point for
+each entry and exit of the crossing areas, of the penumbra (penumbra-entry and
+penumbra-exit) and of the umbra (eclipse-entry and eclipse-exit). These times
+are refined between two points of the trajectory as with -events and -eclipses.
+
+with -timescale, the times of the output (trajectory, passes, events, eclipses
+and conjunctions) are given in the time scale TAI, TT, GPS or UT1 instead of
+UTC. The julian day column follows the time scale. TAI-UTC comes from the table
+of leap seconds (or from the file given with -eop) and UT1-UTC from the file
+given with -eop (or from the embedded snapshot). The epochs of the TLE stay in UTC, and
+so do the times of the KML documents.
+
+Passes:
+
+with -passes, inspect gives the windows of visibility of the satellite from the
+ground station given with -station. The columns of the output are:
+
+- satellite identifier
+- AOS time and azimuth
+- TCA time, azimuth, maximum elevation and range (kilometer)
+- LOS time and azimuth
+- duration of the pass
+
+Events:
+
+with -events, inspect gives the time when the satellite enters and exits the
+crossing area. These times are refined between two points of the trajectory so
+that a large interval can be used without losing precision. The columns of the
+output are:
+
+- label of the event
+- satellite identifier
+- entry time
+- exit time
+- duration of the crossing
+
+Eclipses:
+
+with -eclipses, inspect gives the time when the satellite enters and exits the
+penumbra and the umbra of the earth. These times are refined between two points
+of the trajectory. The columns of the output are:
+
+- satellite identifier
+- penumbra entry time
+- umbra entry time
+- umbra exit time
+- penumbra exit time
+- duration of the eclipse (umbra)
+- duration of the eclipse (penumbra and umbra)
+- maximum percentage of the solar disk occulted by the earth
+
+the passes, crossings and eclipses already in progress at the start of the
+period or still in progress at its end are not given since their entry or exit
+time is unknown.
+
+Conjunctions:
+
+with -conjunction, inspect gives the close approaches between the satellite
+given with -s and a second satellite given with -with (both read from the same
+input) or found in a second input file. The distance between both satellites is
+computed every interval and the time of closest approach is refined between two
+points. Only the approaches with a miss distance below -miss (kilometers) are
+given when set. The columns of the output are:
+
+- satellite identifier
+- second satellite identifier
+- time of closest approach
+- miss distance (kilometer)
+- radial, in-track and cross-track components of the miss distance in the
+  orbital frame of the first satellite (kilometer)
+- relative velocity (kilometer/second)
+
+Crossing area:
+
+the crossing area given with -r can be a rectangle (NORTH:EAST:SOUTH:WEST), a
+circle (LAT:LON:RADIUS), a WKT POLYGON/MULTIPOLYGON or a file with a GeoJSON or
+WKT (multi)polygon. The edges of the polygons are followed on the sphere (great
+circle arcs) so that an area can cross the antimeridian. The radius of a circle
+is given in kilometers and the distance to its centre is measured on the
+ellipsoid.
+
+the crossing area can be limited in altitude with -alt MIN:MAX (kilometers).
+Moreover, with -r saa, inspect uses a coarse model of the South Atlantic Anomaly
+whose footprint grows with the altitude of the satellite. The areas are checked
+against the position of the satellite in the system given with -c.
+
+besides the crossing area, multiple named areas can be checked in a single run
+with -a LABEL=AREA (or the areas list of the configuration file). One crossing
+column is added per named area and the events of each area are labeled with its
+label.
 
 Options:
 
   -b       DATE    start date
-  -c       COORD   coordinate system used (geocentric, geodetic, teme/eci)
+  -c       COORD   coordinate system used (geocentric, geodetic, teme/eci, j2000, gcrf)
   -d       TIME    TIME over which calculate the predicted trajectory
-  -f       FORMAT  print predicted trajectory in FORMAT (csv, pipe, json, xml)
+  -f       FORMAT  print predicted trajectory in FORMAT (csv, pipe, json, ndjson, xml, oem, oem-xml, kml, kmz, geojson)
   -i       TIME    TIME between two points on the predicted trajectory
   -r       AREA    check if the predicted trajectory crossed the given AREA
-  -s       SID     satellite identifier
+                   (N:E:S:W, LAT:LON:RADIUS, saa, WKT polygon or GeoJSON/WKT file)
+  -alt     MIN:MAX only check crossing of AREA between MIN and MAX km (0: no limit)
+  -a       NAMED   check if the predicted trajectory crossed a named area given
+                   as LABEL=AREA (can be repeated)
+  -s       SID     satellite identifier (decimal or Alpha-5, eg A0001) or name
   -t       DIR     store a TLE fetched from a remote server in DIR
   -w       FILE    write predicted trajectory in FILE (default to stdout)
+  -tle-out FILE    write the TLE used in FILE (encoded from the parsed elements)
+  -catalog         predict the trajectory of every satellite found in the input
+  -workers N       number of satellites predicted concurrently with -catalog
+  -dir     DIR     write one file per satellite in DIR with -catalog
   -bstar   LIMIT   B-STAR drag coefficient limit
+  -station STATION ground station given as LAT:LON:ALT[:ELEVATION]
+  -passes          print the passes of the satellite over the ground station
+  -events          print the entry and exit time of the satellite in AREA
+  -eclipses        print the entry and exit time of the satellite in the umbra and penumbra
+  -conjunction     print the close approaches between two satellites
+  -with    SID     second satellite identifier or name with -conjunction
+  -miss    KM      only print close approaches with a miss distance below KM
+  -eop     FILE    earth orientation parameters (IERS finals2000A or CelesTrak CSV)
+  -timescale SCALE time scale of the output (utc, tai, tt, gps, ut1)
+  -sun             add the beta angle and the direction of the sun
+  -moon            add the direction of the moon and its angle above the limb
+  -lenient         accept TLE with invalid checksum or without trailing spaces
   -360             longitude are given in range of [0:360[ instead of ]-180:180[
   -dms             convert latitude and longitude to DD°MIN'SEC'' format
   -config          load settings from a configuration file
@@ -86,6 +286,25 @@ $ inspect -c geodetic -dms -d 72h -i 1m /tmp/tle-201481119.txt
 # cross a rectangle draw above a small town in Belgium.
 $ inspect -r 51.0:46.0:49.0:50 -c geodetic -dms -d 72h -i 1m /tmp/tle-201481119.txt
 
+# print the passes of the satellite over a ground station located in Brussels
+# with a minimum elevation of 10° above the horizon
+$ inspect -passes -station 50.85:4.35:0.1:10 -d 72h -i 1m /tmp/tle-201481119.txt
+
+# print the entry and exit time of the satellite in the SAA over two weeks
+$ inspect -events -d 336h -i 1m /tmp/tle-201481119.txt
+
+# use the polygon(s) of a GeoJSON file as crossing area
+$ inspect -r etc/saa.geojson -events -d 72h -i 1m /tmp/tle-201481119.txt
+
+# print the entry and exit time of the satellite within 500km of Brussels
+$ inspect -r 50.85:4.35:500 -events -d 72h -i 1m /tmp/tle-201481119.txt
+
+# check the crossing of two named areas in addition to the SAA
+$ inspect -a bru=50.85:4.35:500 -a hou=29.76:-95.37:500 -d 72h -i 1m /tmp/tle-201481119.txt
+
+# print the approaches closer than 10km between the ISS and another satellite
+$ inspect -conjunction -with 48274 -miss 10 -d 72h -i 1m https://celestrak.com/NORAD/elements/stations.txt
+
 # use a configuration file instead of command line options
 $ inspect -config etc/inspect.toml
 `
@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"os"
 	"path"
+	"regexp"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/eop"
+	"github.com/busoc/inspect/timescale"
 	"github.com/midbel/toml"
 )
 
@@ -47,6 +52,65 @@ func (d *Duration) String() string {
 	return d.Duration.String()
 }
 
+// satellite selects a satellite by its catalog number given in decimal or in
+// the Alpha-5 scheme, or by its name. A name matches when it equals, contains
+// or matches (as a regular expression) the name of the satellite ignoring
+// case.
+type satellite struct {
+	Sid  int
+	Name string
+
+	re *regexp.Regexp
+}
+
+func (s *satellite) Set(str string) error {
+	if n, err := celest.ParseCatalog(str); err == nil {
+		*s = satellite{Sid: n}
+		return nil
+	}
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return fmt.Errorf("empty satellite name")
+	}
+	re, err := regexp.Compile("(?i)" + str)
+	if err != nil {
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(str))
+	}
+	*s = satellite{Name: str, re: re}
+	return nil
+}
+
+func (s *satellite) String() string {
+	switch {
+	case s.Sid == 0:
+		return s.Name
+	case s.Name == "":
+		return celest.FormatCatalog(s.Sid)
+	default:
+		return fmt.Sprintf("%s (%s)", celest.FormatCatalog(s.Sid), s.Name)
+	}
+}
+
+// IsZero reports whether no satellite has been selected.
+func (s *satellite) IsZero() bool {
+	return s.Sid == 0 && s.Name == ""
+}
+
+// Match reports whether the element belongs to the selected satellite. Any
+// element matches when no satellite has been selected.
+func (s *satellite) Match(e *celest.Element) bool {
+	if s.IsZero() {
+		return true
+	}
+	if s.re == nil {
+		return e.Sid == s.Sid
+	}
+	if strings.EqualFold(e.Name, s.Name) || strings.Contains(strings.ToLower(e.Name), strings.ToLower(s.Name)) {
+		return true
+	}
+	return s.re.MatchString(e.Name)
+}
+
 func init() {
 	log.SetOutput(os.Stderr)
 	log.SetPrefix(fmt.Sprintf("[%s-%s] ", Program, Version))
@@ -57,21 +121,45 @@ func init() {
 }
 
 type Settings struct {
-	Area     rect     `toml:"area"`
+	Area     area     `toml:"area"`
+	Areas    zones    `toml:"areas"`
 	File     string   `toml:"file"`
 	Source   string   `toml:"tle"`
 	Temp     string   `toml:"tmpdir"`
 	Sid      int      `toml:"satellite"`
+	Name     string   `toml:"satellite-name"`
 	Period   Duration `toml:"duration"`
 	Interval Duration `toml:"interval"`
 	BStar    float64  `toml:"bstar"`
+	Station  station  `toml:"station"`
+	Passes   bool     `toml:"passes"`
+	Events   bool     `toml:"events"`
+	Eclipses bool     `toml:"eclipses"`
+	Lenient  bool     `toml:"lenient"`
+	TLEOut   string   `toml:"tle-out"`
+	Catalog  bool     `toml:"catalog"`
+	Workers  int      `toml:"workers"`
+	Dir      string   `toml:"dir"`
+	EOP      string   `toml:"eop"`
+
+	Conjunction bool    `toml:"conjunction"`
+	With        int     `toml:"with"`
+	WithName    string  `toml:"with-name"`
+	Miss        float64 `toml:"miss"`
 
 	Print printer `toml:"format"`
+
+	// satellites selected with -s and -with or by the keys of the configuration
+	// file
+	sat  satellite
+	with satellite
 }
 
 func (s *Settings) Update(f string) error {
-	err := toml.DecodeFile(f, s)
-	return checkError(err, nil)
+	if err := toml.DecodeFile(f, s); err != nil {
+		return checkError(err, nil)
+	}
+	return s.selectSatellites()
 	// r, err := os.Open(f)
 	// if err != nil {
 	// 	return checkError(err, nil)
@@ -84,11 +172,35 @@ func (s *Settings) Update(f string) error {
 	// return nil
 }
 
+// selectSatellites sets the satellites selected by the keys of the
+// configuration file. A name (or a catalog number in the Alpha-5 scheme) takes
+// precedence over a catalog number.
+func (s *Settings) selectSatellites() error {
+	for _, x := range []struct {
+		sat  *satellite
+		sid  int
+		name string
+	}{
+		{sat: &s.sat, sid: s.Sid, name: s.Name},
+		{sat: &s.with, sid: s.With, name: s.WithName},
+	} {
+		switch {
+		case x.name != "":
+			if err := x.sat.Set(x.name); err != nil {
+				return badUsage(err.Error())
+			}
+		case x.sid != 0:
+			*x.sat = satellite{Sid: x.sid}
+		}
+	}
+	return nil
+}
+
 func main() {
 	s := Settings{
 		Area:     SAA,
 		Temp:     os.TempDir(),
-		Sid:      DefaultSid,
+		sat:      satellite{Sid: DefaultSid},
 		Period:   Duration{time.Hour * 72},
 		Interval: Duration{time.Minute},
 		BStar:    -0.001,
@@ -99,12 +211,30 @@ func main() {
 	flag.StringVar(&s.Print.Syst, "c", "", "system")
 	flag.BoolVar(&s.Print.Round, "360", false, "round")
 	flag.BoolVar(&s.Print.DMS, "dms", false, "dms")
+	flag.StringVar(&s.Print.Time, "timescale", "", "time scale of the output")
+	flag.BoolVar(&s.Print.Sun, "sun", false, "add beta angle and direction of the sun")
+	flag.BoolVar(&s.Print.Moon, "moon", false, "add direction of the moon and angle above the limb")
 	flag.StringVar(&s.Temp, "t", s.Temp, "temp dir")
-	flag.IntVar(&s.Sid, "s", s.Sid, "satellite number")
+	flag.Var(&s.sat, "s", "satellite number or name")
 	flag.Var(&s.Area, "r", "saa area")
+	flag.Var(&s.Area.Alt, "alt", "altitude range of area")
+	flag.Var(&s.Areas, "a", "named area")
+	flag.Var(&s.Station, "station", "ground station")
+	flag.BoolVar(&s.Passes, "passes", false, "compute passes over ground station")
+	flag.BoolVar(&s.Events, "events", false, "compute entry and exit of crossing area")
+	flag.BoolVar(&s.Eclipses, "eclipses", false, "compute entry and exit of umbra and penumbra")
+	flag.BoolVar(&s.Lenient, "lenient", false, "do not verify checksum of TLE")
 	flag.Var(&s.Period, "d", "time range")
 	flag.Var(&s.Interval, "i", "time interval")
 	flag.StringVar(&s.File, "w", "", "write trajectory to file (stdout if not provided)")
+	flag.StringVar(&s.TLEOut, "tle-out", "", "write TLE used to file")
+	flag.BoolVar(&s.Catalog, "catalog", false, "predict every satellite of the input")
+	flag.IntVar(&s.Workers, "workers", runtime.NumCPU(), "number of satellites predicted concurrently")
+	flag.StringVar(&s.Dir, "dir", "", "write one file per satellite in directory")
+	flag.StringVar(&s.EOP, "eop", "", "earth orientation parameters file")
+	flag.BoolVar(&s.Conjunction, "conjunction", false, "compute close approaches between two satellites")
+	flag.Var(&s.with, "with", "second satellite number or name")
+	flag.Float64Var(&s.Miss, "miss", 0, "maximum miss distance of close approaches")
 	base := flag.String("b", "", "base time")
 	delay := flag.Bool("y", false, "")
 	info := flag.Bool("info", false, "print info about the given TLE")
@@ -148,26 +278,96 @@ func main() {
 	} else {
 		sources = flag.Args()
 	}
+	if err := s.Area.Load(); err != nil {
+		Exit(checkError(err, nil))
+	}
+	for i := range s.Areas {
+		if err := s.Areas[i].Load(); err != nil {
+			Exit(checkError(err, nil))
+		}
+		s.Areas[i].Syst = s.Print.Syst
+	}
 	s.Area.Syst = s.Print.Syst
+	switch sc, err := timescale.Parse(s.Print.Time); {
+	case err == nil:
+		s.Print.scale = sc
+	default:
+		Exit(badUsage(err.Error()))
+	}
+	if err := s.Print.checkBodies(); err != nil {
+		Exit(badUsage(err.Error()))
+	}
+	if s.EOP != "" {
+		t, err := eop.Load(s.EOP)
+		if err != nil {
+			Exit(checkError(err, nil))
+		}
+		eop.Use(t)
+	}
 
 	log.Printf("%s-%s (build: %s)", Program, Version, BuildTime)
 	log.Printf("settings: trajectory duration %s", s.Period.Duration)
 	log.Printf("settings: trajectory interval %s", s.Interval.Duration)
-	log.Printf("settings: satellite identifier %d", s.Sid)
+	log.Printf("settings: satellite identifier %s", s.sat.String())
 	log.Printf("settings: bstar-drag coefficient limit %.6f", s.BStar)
 	log.Printf("settings: crossing area %s", s.Area.String())
+	for _, a := range s.Areas {
+		log.Printf("settings: named area %s %s", a.Label, a.String())
+	}
 	log.Printf("settings: latlon system %s", s.Print.Syst)
+	log.Printf("settings: time scale %s", s.Print.scale)
+	log.Printf("settings: sun columns %t, moon columns %t", s.Print.Sun, s.Print.Moon)
+	if t := eop.Current(); t != nil {
+		file := s.EOP
+		if t.Embedded() {
+			file = "embedded"
+		}
+		from, to := t.Range()
+		log.Printf("settings: earth orientation parameters %s (%s - %s)", file, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	if s.Print.Syst == "gcrf" && !eop.Current().Offsets() {
+		log.Printf("settings: no celestial pole offsets given with -eop, gcrf is j2000")
+	}
+	if s.Passes {
+		log.Printf("settings: ground %s", s.Station.String())
+	}
+	if s.Conjunction {
+		log.Printf("settings: second satellite %s", s.with.String())
+		log.Printf("settings: maximum miss distance %.3fkm", s.Miss)
+	}
 
-	t, err := fetchTLE(sources, s.Temp, s.Sid, s.BStar)
-	if err != nil {
-		Exit(checkError(err, nil))
+	if s.Catalog {
+		if err := runCatalog(sources, s, bt, *delay); err != nil {
+			Exit(checkError(err, nil))
+		}
+		return
 	}
-	t.Base = bt
 
-	rs, err := t.Predict(s.Period.Duration, s.Interval.Duration, &s.Area, *delay)
+	var (
+		t, o *celest.Trajectory
+		err  error
+	)
+	if s.Conjunction {
+		t, o, err = fetchPair(sources, &s)
+	} else {
+		t, err = fetchTLE(sources, &s)
+	}
 	if err != nil {
 		Exit(checkError(err, nil))
 	}
+	ts := []*celest.Trajectory{t}
+	if o != nil {
+		ts = append(ts, o)
+	}
+	for _, t := range ts {
+		t.Base = bt
+	}
+	if s.TLEOut != "" {
+		if err := writeTLE(s.TLEOut, ts...); err != nil {
+			Exit(checkError(err, nil))
+		}
+	}
+
 	var w io.Writer
 	digest := md5.New()
 	switch f, err := os.Create(s.File); {
@@ -184,6 +384,55 @@ func main() {
 	default:
 		Exit(checkError(err, nil))
 	}
+	if s.Conjunction {
+		n, err := printConjunctions(w, t, o, s, *delay)
+		if err != nil {
+			Exit(checkError(err, nil))
+		}
+		log.Printf("%d close approaches between %s and %s", n, s.sat.String(), s.with.String())
+		log.Printf("md5: %x", digest.Sum(nil))
+		return
+	}
+	if s.Passes {
+		n, err := printPasses(w, t, s, *delay)
+		if err != nil {
+			Exit(checkError(err, nil))
+		}
+		log.Printf("%d passes over %s", n, s.Station.String())
+		log.Printf("md5: %x", digest.Sum(nil))
+		return
+	}
+	if s.Events {
+		n, err := printEvents(w, t, s, *delay)
+		if err != nil {
+			Exit(checkError(err, nil))
+		}
+		log.Printf("%d crossing during trajectory (%s)", n, s.Area.String())
+		if len(s.Areas) > 0 {
+			log.Printf("named areas: %s", s.Areas.String())
+		}
+		log.Printf("md5: %x", digest.Sum(nil))
+		return
+	}
+	if s.Eclipses {
+		n, err := printEclipses(w, t, s, *delay)
+		if err != nil {
+			Exit(checkError(err, nil))
+		}
+		log.Printf("%d eclipses during trajectory", n)
+		log.Printf("md5: %x", digest.Sum(nil))
+		return
+	}
+
+	if strings.ToLower(s.Print.Format) == "geojson" {
+		if s.Print.events, err = s.Print.geoEvents(t, s, *delay); err != nil {
+			Exit(checkError(err, nil))
+		}
+	}
+	rs, err := t.Predict(s.Period.Duration, s.Interval.Duration, &s.Area, *delay, s.Areas.Areas()...)
+	if err != nil {
+		Exit(checkError(err, nil))
+	}
 	m, err := s.Print.Print(w, rs, s)
 	if err != nil {
 		Exit(checkError(err, nil))
@@ -192,71 +441,149 @@ func main() {
 	log.Printf("%d positions predicted", m.Points)
 	log.Printf("%d eclipses during trajectory", m.Eclipse)
 	log.Printf("%d crossing during trajectory (%s)", m.Crossing, s.Area.String())
+	for _, a := range s.Areas {
+		log.Printf("%d crossing during trajectory (%s: %s)", m.Areas[a.Label], a.Label, a.String())
+	}
 
 	log.Printf("md5: %x", digest.Sum(nil))
 }
 
 func printInfos(sources []string, s *Settings) error {
 	const (
-		row  = "%d | %s | %s | %s | %12s | %d"
+		row  = "%s | %-24s | %s | %s | %s | %12s | %d"
 		tfmt = "2006-01-02 15:04:05"
 	)
-	t, err := fetchTLE(sources, s.Temp, s.Sid, s.BStar)
+	t, err := fetchTLE(sources, s)
 	if err != nil {
 		return err
 	}
 	for _, i := range t.Infos(s.Period.Duration, s.Interval.Duration) {
 		delta := i.Ends.Sub(i.Starts)
 		c := delta / s.Interval.Duration
-		fmt.Printf(row, i.Sid, i.When.Format(tfmt), i.Starts.Format(tfmt), i.Ends.Format(tfmt), delta, c)
+		fmt.Printf(row, celest.FormatCatalog(i.Sid), i.Name, i.When.Format(tfmt), i.Starts.Format(tfmt), i.Ends.Format(tfmt), delta, c)
 		fmt.Println()
 	}
 	return nil
 }
 
-func fetchTLE(ps []string, copydir string, sid int, bstar float64) (*celest.Trajectory, error) {
+func fetchTLE(ps []string, s *Settings) (*celest.Trajectory, error) {
+	return fetchSatellite(ps, s, &s.sat)
+}
+
+// fetchSatellite reads the elements of the satellite sat from the sources.
+// sat is updated with the number and name of the satellite found.
+func fetchSatellite(ps []string, s *Settings, sat *satellite) (*celest.Trajectory, error) {
+	var t celest.Trajectory
+	if s.Lenient {
+		t.Mode = celest.Lenient
+	}
+	err := fetch(ps, s.Temp, func(r io.Reader, format string) error {
+		return t.ScanFilter(r, format, s.BStar, sat.Match)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Elements()) > 0 {
+		*sat = satellite{Sid: t.Sid(), Name: t.Name()}
+	}
+	return &t, nil
+}
+
+// fetch calls scan with the content of each local file or with the content of
+// the remote file given as first source (stored in copydir). The format is
+// empty for local files.
+func fetch(ps []string, copydir string, scan func(io.Reader, string) error) error {
 	if len(ps) == 0 {
-		return nil, fmt.Errorf("no input files given")
+		return fmt.Errorf("no input files given")
 	}
-	var t celest.Trajectory
 	digest := md5.New()
 	if resp, err := http.Get(ps[0]); err == nil {
 		defer resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
-			return nil, fetchError(ps[0], resp.StatusCode)
+			return fetchError(ps[0], resp.StatusCode)
 		}
 		var w io.Writer = digest
 		suffix := "-" + time.Now().Format("20060102_150405")
 		if err := os.MkdirAll(copydir, 0755); err != nil && !os.IsExist(err) {
-			return nil, checkError(err, nil)
+			return checkError(err, nil)
 		}
 		if f, err := os.Create(path.Join(copydir, path.Base(ps[0]+suffix))); err == nil {
 			defer f.Close()
 			w = io.MultiWriter(f, w)
 		}
-		if err := t.Scan(io.TeeReader(resp.Body, w), sid, bstar); err != nil {
-			return nil, checkError(err, nil)
+		format := formatFromType(resp.Header.Get("content-type"))
+		if err := scan(io.TeeReader(resp.Body, w), format); err != nil {
+			return checkError(err, nil)
 		}
 		log.Printf("parsing TLE from %s done (md5: %x, last-modified: %s)", ps[0], digest.Sum(nil), resp.Header.Get("last-modified"))
 	} else {
 		for _, p := range ps {
 			r, err := os.Open(p)
 			if err != nil {
-				return nil, checkError(err, nil)
+				return checkError(err, nil)
 			}
-			if err := t.Scan(io.TeeReader(r, digest), sid, bstar); err != nil {
-				return nil, checkError(err, nil)
+			if err := scan(io.TeeReader(r, digest), ""); err != nil {
+				return checkError(err, nil)
 			}
-			s, err := r.Stat()
+			i, err := r.Stat()
 			if err != nil {
-				return nil, checkError(err, nil)
+				return checkError(err, nil)
 			}
-			log.Printf("parsing TLE from %s done (md5: %x, last-modified: %s)", p, digest.Sum(nil), s.ModTime().Format(time.RFC1123))
+			log.Printf("parsing TLE from %s done (md5: %x, last-modified: %s)", p, digest.Sum(nil), i.ModTime().Format(time.RFC1123))
 			r.Close()
 			digest.Reset()
 		}
 	}
-	return &t, nil
+	return nil
+}
+
+// writeTLE writes the elements of the trajectories to file. The rows are
+// encoded from the fields of the elements. The elements that can not be
+// encoded are skipped and reported.
+func writeTLE(file string, ts ...*celest.Trajectory) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var n int
+	for _, t := range ts {
+		for _, e := range t.Elements() {
+			rs, err := e.Format()
+			if err != nil {
+				log.Printf("%s: element skipped: %s", celest.FormatCatalog(e.Sid), err)
+				continue
+			}
+			for _, r := range rs {
+				if _, err := fmt.Fprintln(f, r); err != nil {
+					return err
+				}
+			}
+			n++
+		}
+	}
+	log.Printf("%d TLE written to %s", n, file)
+	return f.Close()
+}
+
+// formatFromType gives the format of the elements from the content type of a
+// response. An empty format let celest detect it from the content.
+func formatFromType(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ""
+	}
+	switch mt {
+	case "application/xml", "text/xml":
+		return celest.FormatXML
+	case "application/json":
+		return celest.FormatJSON
+	case "text/csv":
+		return celest.FormatCSV
+	default:
+		return ""
+	}
 }
 
 func transform(p *celest.Point, syst string) *celest.Point {
@@ -272,6 +599,12 @@ func transform(p *celest.Point, syst string) *celest.Point {
 		return &g
 	case "teme", "eci":
 		return p
+	case "j2000", "eme2000":
+		g := p.J2000()
+		return &g
+	case "gcrf":
+		g := p.GCRF()
+		return &g
 	case "dublin":
 		g := p.Dublin()
 		return &g
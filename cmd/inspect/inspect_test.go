@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/busoc/inspect"
+)
+
+func Example_satellite() {
+	var (
+		sat satellite
+		set = flag.NewFlagSet("inspect", flag.ContinueOnError)
+		iss = &celest.Element{Sid: 25544, Name: "ISS (ZARYA)"}
+	)
+	set.Var(&sat, "s", "satellite number or name")
+	for _, str := range []string{"25544", "A0001", "zarya", "^iss", "hubble"} {
+		if err := set.Parse([]string{"-s", str}); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%s: %t", sat.String(), sat.Match(iss))
+		fmt.Println()
+	}
+	// Output:
+	// 25544: true
+	// A0001: false
+	// zarya: true
+	// ^iss: true
+	// hubble: false
+}
+
+func ExampleSettings_Update() {
+	for _, cfg := range []string{
+		"satellite = 25544\nwith = 25545\n",
+		"satellite-name = \"A0001\"\n",
+		"satellite = 25544\nsatellite-name = \"^iss\"\nwith-name = \"hubble\"\n",
+		"duration = \"1h\"\n",
+	} {
+		f, err := ioutil.TempFile("", "inspect*.toml")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		f.WriteString(cfg)
+		f.Close()
+
+		s := Settings{sat: satellite{Sid: DefaultSid}}
+		err = s.Update(f.Name())
+		os.Remove(f.Name())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("satellite: %q, with: %q", s.sat.String(), s.with.String())
+		fmt.Println()
+	}
+	iss := &celest.Element{Sid: 25544, Name: "ISS (ZARYA)"}
+	s := Settings{sat: satellite{Sid: DefaultSid}}
+	s.Name = "zarya"
+	if err := s.selectSatellites(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("zarya matches ISS (ZARYA): %t", s.sat.Match(iss))
+	fmt.Println()
+	// Output:
+	// satellite: "25544", with: "25545"
+	// satellite: "A0001", with: ""
+	// satellite: "^iss", with: "hubble"
+	// satellite: "25544", with: ""
+	// zarya matches ISS (ZARYA): true
+}
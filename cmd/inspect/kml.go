@@ -0,0 +1,287 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/shape"
+)
+
+const (
+	kmlNS   = "http://www.opengis.net/kml/2.2"
+	kmlGxNS = "http://www.google.com/kml/ext/2.2"
+)
+
+const (
+	styleTrack   = "track"
+	styleEclipse = "eclipse"
+	styleSaa     = "saa"
+	styleArea    = "area"
+	styleSat     = "satellite"
+)
+
+type kml struct {
+	XMLName xml.Name    `xml:"kml"`
+	NS      string      `xml:"xmlns,attr"`
+	Gx      string      `xml:"xmlns:gx,attr"`
+	Doc     kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Desc    string      `xml:"description,omitempty"`
+	Styles  []kmlStyle  `xml:"Style"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	Id    string    `xml:"id,attr"`
+	Line  *kmlColor `xml:"LineStyle,omitempty"`
+	Poly  *kmlColor `xml:"PolyStyle,omitempty"`
+	Icon  *kmlIcon  `xml:"IconStyle,omitempty"`
+	Label *kmlColor `xml:"LabelStyle,omitempty"`
+}
+
+type kmlColor struct {
+	Color string  `xml:"color"`
+	Width float64 `xml:"width,omitempty"`
+}
+
+type kmlIcon struct {
+	Href string `xml:"Icon>href"`
+}
+
+type kmlFolder struct {
+	Name   string         `xml:"name"`
+	Places []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name  string       `xml:"name,omitempty"`
+	Style string       `xml:"styleUrl"`
+	Line  *kmlLine     `xml:"LineString,omitempty"`
+	Multi *kmlPolygons `xml:"MultiGeometry,omitempty"`
+	Track *kmlTrack    `xml:"gx:Track,omitempty"`
+}
+
+type kmlLine struct {
+	Tessellate int    `xml:"tessellate"`
+	Coords     string `xml:"coordinates"`
+}
+
+type kmlPolygons struct {
+	Polygons []kmlPolygon `xml:"Polygon"`
+}
+
+type kmlPolygon struct {
+	Tessellate int      `xml:"tessellate"`
+	Outer      string   `xml:"outerBoundaryIs>LinearRing>coordinates"`
+	Inner      []string `xml:"innerBoundaryIs>LinearRing>coordinates,omitempty"`
+}
+
+type kmlTrack struct {
+	Mode   string   `xml:"altitudeMode"`
+	When   []string `xml:"when"`
+	Coords []string `xml:"gx:coord"`
+}
+
+// segment is a part of the ground track drawn with the same style.
+type segment struct {
+	Style  string
+	Coords []string
+}
+
+func (g *segment) Add(lat, lon float64) {
+	g.Coords = append(g.Coords, fmt.Sprintf("%.6f,%.6f", lon, lat))
+}
+
+func styleOf(p *celest.Point) string {
+	switch {
+	case p.Saa:
+		return styleSaa
+	case p.Total:
+		return styleEclipse
+	default:
+		return styleTrack
+	}
+}
+
+// groundTrack splits the ground track into segments at the antimeridian and
+// each time the satellite enters or leaves the eclipse or the crossing area.
+func groundTrack(ps []*celest.Point) []segment {
+	var (
+		gs   []segment
+		g    *segment
+		prev *celest.Point
+	)
+	for _, p := range ps {
+		if g == nil {
+			g = &segment{Style: styleOf(p)}
+			g.Add(p.Lat, p.Lon)
+			prev = p
+			continue
+		}
+		if s := styleOf(p); s != g.Style {
+			gs = append(gs, *g)
+			g = &segment{Style: s}
+			g.Add(prev.Lat, prev.Lon)
+		}
+		if lat, edge, ok := shape.Antimeridian(prev.Lat, prev.Lon, p.Lat, p.Lon); ok {
+			g.Add(lat, edge)
+			gs = append(gs, *g)
+			g = &segment{Style: g.Style}
+			g.Add(lat, -edge)
+		}
+		g.Add(p.Lat, p.Lon)
+		prev = p
+	}
+	if g != nil {
+		gs = append(gs, *g)
+	}
+	return gs
+}
+
+func kmlRing(r shape.Ring) string {
+	vs := make([]string, len(r))
+	for i, v := range r {
+		vs[i] = fmt.Sprintf("%.6f,%.6f", v.Lon, v.Lat)
+	}
+	return strings.Join(vs, " ")
+}
+
+func kmlArea(label string, a *area) kmlPlacemark {
+	var ps kmlPolygons
+	for _, p := range a.Outline() {
+		if len(p) == 0 {
+			continue
+		}
+		g := kmlPolygon{Tessellate: 1, Outer: kmlRing(p[0])}
+		for _, r := range p[1:] {
+			g.Inner = append(g.Inner, kmlRing(r))
+		}
+		ps.Polygons = append(ps.Polygons, g)
+	}
+	return kmlPlacemark{
+		Name:  label,
+		Style: "#" + styleArea,
+		Multi: &ps,
+	}
+}
+
+func kmlStyles() []kmlStyle {
+	return []kmlStyle{
+		{Id: styleTrack, Line: &kmlColor{Color: "ff00ffff", Width: 2}},
+		{Id: styleEclipse, Line: &kmlColor{Color: "ff808080", Width: 2}},
+		{Id: styleSaa, Line: &kmlColor{Color: "ff0000ff", Width: 3}},
+		{Id: styleArea, Line: &kmlColor{Color: "ff0000ff", Width: 1}, Poly: &kmlColor{Color: "400000ff"}},
+		{
+			Id:    styleSat,
+			Icon:  &kmlIcon{Href: "http://maps.google.com/mapfiles/kml/shapes/airports.png"},
+			Label: &kmlColor{Color: "ffffffff"},
+		},
+	}
+}
+
+// printKML writes the ground track of the predicted trajectory as a KML
+// document (or KMZ archive when zipped) with the crossing area(s) and an
+// animated track of the satellite.
+func (pt printer) printKML(w io.Writer, ps <-chan *celest.Result, s Settings, zipped bool) (*meta, error) {
+	var (
+		m     meta
+		sid   string
+		track kmlTrack
+		all   []*celest.Point
+	)
+	syst := pt.Syst
+	if pt.rawFormat() {
+		syst = "geodetic"
+	}
+	track.Mode = "absolute"
+	for r := range ps {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
+		m.TLE++
+		m.Points += len(r.Points)
+		sid = celest.FormatCatalog(r.Sid)
+		if r.Name != "" {
+			sid = r.Name
+		}
+		for _, p := range r.Points {
+			// the times of a KML document are always in UTC
+			p = transform(p, syst)
+			m.count(p)
+			m.cross(pt.labels, p.Areas)
+
+			all = append(all, p)
+			track.When = append(track.When, p.When.Format(time.RFC3339Nano))
+			track.Coords = append(track.Coords, fmt.Sprintf("%.6f %.6f %.1f", p.Lon, p.Lat, p.Alt*1000))
+		}
+	}
+
+	var trace kmlFolder
+	trace.Name = "ground track"
+	for _, g := range groundTrack(all) {
+		k := kmlPlacemark{
+			Style: "#" + g.Style,
+			Line:  &kmlLine{Tessellate: 1, Coords: strings.Join(g.Coords, " ")},
+		}
+		trace.Places = append(trace.Places, k)
+	}
+
+	var areas kmlFolder
+	areas.Name = "areas"
+	areas.Places = append(areas.Places, kmlArea(s.Area.String(), &s.Area))
+	for i := range s.Areas {
+		areas.Places = append(areas.Places, kmlArea(s.Areas[i].Label, &s.Areas[i]))
+	}
+
+	sat := kmlFolder{
+		Name: "satellite",
+		Places: []kmlPlacemark{
+			{Name: sid, Style: "#" + styleSat, Track: &track},
+		},
+	}
+	doc := kml{
+		NS: kmlNS,
+		Gx: kmlGxNS,
+		Doc: kmlDocument{
+			Name:    fmt.Sprintf("%s-%s: %s", Program, Version, sid),
+			Desc:    fmt.Sprintf("trajectory of %s over %s", sid, s.Period.Duration),
+			Styles:  kmlStyles(),
+			Folders: []kmlFolder{trace, areas, sat},
+		},
+	}
+	if !zipped {
+		return &m, encodeKML(w, doc)
+	}
+	z := zip.NewWriter(w)
+	f, err := z.Create("doc.kml")
+	if err != nil {
+		return nil, err
+	}
+	if err := encodeKML(f, doc); err != nil {
+		return nil, err
+	}
+	return &m, z.Close()
+}
+
+func encodeKML(w io.Writer, doc kml) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
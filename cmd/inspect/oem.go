@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect"
+)
+
+const (
+	oemVersion = "2.0"
+	oemTime    = "2006-01-02T15:04:05.000000"
+)
+
+type oemMeta struct {
+	Name   string `xml:"OBJECT_NAME"`
+	Id     string `xml:"OBJECT_ID"`
+	Center string `xml:"CENTER_NAME"`
+	Frame  string `xml:"REF_FRAME"`
+	System string `xml:"TIME_SYSTEM"`
+	Starts string `xml:"START_TIME"`
+	Ends   string `xml:"STOP_TIME"`
+}
+
+type oemState struct {
+	Epoch string  `xml:"EPOCH"`
+	X     float64 `xml:"X"`
+	Y     float64 `xml:"Y"`
+	Z     float64 `xml:"Z"`
+	Vx    float64 `xml:"X_DOT"`
+	Vy    float64 `xml:"Y_DOT"`
+	Vz    float64 `xml:"Z_DOT"`
+}
+
+type oemSegment struct {
+	XMLName  xml.Name   `xml:"segment"`
+	Meta     oemMeta    `xml:"metadata"`
+	Comments []string   `xml:"data>COMMENT"`
+	States   []oemState `xml:"data>stateVector"`
+}
+
+// oemFrame gives the reference frame of the OEM: TEME for the raw output of
+// SGP4, EME2000 or GCRF for the inertial frames, ITRF (earth fixed) otherwise.
+func (pt printer) oemFrame() string {
+	switch strings.ToLower(pt.Syst) {
+	case "teme", "eci":
+		return "TEME"
+	case "j2000", "eme2000":
+		return "EME2000"
+	case "gcrf":
+		return "GCRF"
+	default:
+		return "ITRF"
+	}
+}
+
+func (pt printer) oemSegment(r *celest.Result) oemSegment {
+	var g oemSegment
+	for _, t := range r.TLE {
+		g.Comments = append(g.Comments, t)
+	}
+	frame := pt.oemFrame()
+	for _, p := range r.Points {
+		switch frame {
+		case "TEME":
+		case "ITRF":
+			c := p.CNES()
+			p = &c
+		default:
+			p = transform(p, pt.Syst)
+		}
+		p = pt.retime(p)
+		s := oemState{
+			Epoch: p.When.Format(oemTime),
+			X:     p.Lat,
+			Y:     p.Lon,
+			Z:     p.Alt,
+			Vx:    p.Vx,
+			Vy:    p.Vy,
+			Vz:    p.Vz,
+		}
+		g.States = append(g.States, s)
+	}
+	name := r.Name
+	if name == "" {
+		name = celest.FormatCatalog(r.Sid)
+	}
+	g.Meta = oemMeta{
+		Name:   name,
+		Id:     celest.FormatCatalog(r.Sid),
+		Center: "EARTH",
+		Frame:  frame,
+		System: pt.scale.String(),
+	}
+	if n := len(g.States); n > 0 {
+		g.Meta.Starts, g.Meta.Ends = g.States[0].Epoch, g.States[n-1].Epoch
+	}
+	return g
+}
+
+// printOEM writes the predicted trajectory as a CCSDS Orbit Ephemeris Message
+// (KVN) with one segment per TLE used.
+func (pt printer) printOEM(w io.Writer, ps <-chan *celest.Result) (*meta, error) {
+	ws := bufio.NewWriter(w)
+	defer ws.Flush()
+
+	fmt.Fprintf(ws, "CCSDS_OEM_VERS = %s\n", oemVersion)
+	fmt.Fprintf(ws, "CREATION_DATE = %s\n", time.Now().UTC().Format(oemTime))
+	fmt.Fprintf(ws, "ORIGINATOR = %s-%s\n", Program, Version)
+
+	var m meta
+	for r := range ps {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
+		m.TLE++
+		m.Points += len(r.Points)
+		for _, p := range r.Points {
+			m.count(p)
+			m.cross(pt.labels, p.Areas)
+		}
+
+		g := pt.oemSegment(r)
+		fmt.Fprintln(ws)
+		fmt.Fprintln(ws, "META_START")
+		fmt.Fprintf(ws, "OBJECT_NAME = %s\n", g.Meta.Name)
+		fmt.Fprintf(ws, "OBJECT_ID = %s\n", g.Meta.Id)
+		fmt.Fprintf(ws, "CENTER_NAME = %s\n", g.Meta.Center)
+		fmt.Fprintf(ws, "REF_FRAME = %s\n", g.Meta.Frame)
+		fmt.Fprintf(ws, "TIME_SYSTEM = %s\n", g.Meta.System)
+		fmt.Fprintf(ws, "START_TIME = %s\n", g.Meta.Starts)
+		fmt.Fprintf(ws, "STOP_TIME = %s\n", g.Meta.Ends)
+		fmt.Fprintln(ws, "META_STOP")
+		fmt.Fprintln(ws)
+		for _, c := range g.Comments {
+			fmt.Fprintf(ws, "COMMENT %s\n", c)
+		}
+		for _, s := range g.States {
+			fmt.Fprintf(ws, "%s %.6f %.6f %.6f %.9f %.9f %.9f\n", s.Epoch, s.X, s.Y, s.Z, s.Vx, s.Vy, s.Vz)
+		}
+	}
+	return &m, ws.Flush()
+}
+
+// printOEMXML writes the predicted trajectory as a CCSDS Orbit Ephemeris
+// Message (XML) with one segment per TLE used.
+func (pt printer) printOEMXML(w io.Writer, ps <-chan *celest.Result) (*meta, error) {
+	var (
+		m    meta
+		e    = xml.NewEncoder(w)
+		root = xml.StartElement{
+			Name: xml.Name{Local: "oem"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "id"}, Value: "CCSDS_OEM_VERS"},
+				{Name: xml.Name{Local: "version"}, Value: oemVersion},
+			},
+		}
+		body = xml.StartElement{Name: xml.Name{Local: "body"}}
+	)
+	e.Indent("", "  ")
+
+	header := struct {
+		XMLName    xml.Name `xml:"header"`
+		Created    string   `xml:"CREATION_DATE"`
+		Originator string   `xml:"ORIGINATOR"`
+	}{
+		Created:    time.Now().UTC().Format(oemTime),
+		Originator: Program + "-" + Version,
+	}
+	if err := e.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := e.Encode(header); err != nil {
+		return nil, err
+	}
+	if err := e.EncodeToken(body); err != nil {
+		return nil, err
+	}
+	for r := range ps {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
+		m.TLE++
+		m.Points += len(r.Points)
+		for _, p := range r.Points {
+			m.count(p)
+			m.cross(pt.labels, p.Areas)
+		}
+		if err := e.Encode(pt.oemSegment(r)); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range []xml.Token{body.End(), root.End()} {
+		if err := e.EncodeToken(t); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	_, err := io.WriteString(w, "\n")
+	return &m, err
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect"
+	"github.com/midbel/linewriter"
+)
+
+type station struct {
+	Label     string  `toml:"label"`
+	Lat       float64 `toml:"latitude"`
+	Lon       float64 `toml:"longitude"`
+	Alt       float64 `toml:"altitude"`
+	Elevation float64 `toml:"elevation"`
+}
+
+func (s *station) Set(str string) error {
+	vs := strings.Split(str, ":")
+	if n := len(vs); n < 3 || n > 4 {
+		return fmt.Errorf("invalid station %s (lat:lon:alt[:elevation])", str)
+	}
+	fs := []*float64{&s.Lat, &s.Lon, &s.Alt, &s.Elevation}
+	for i := range vs {
+		f, err := strconv.ParseFloat(vs[i], 64)
+		if err != nil {
+			return err
+		}
+		*fs[i] = f
+	}
+	return nil
+}
+
+func (s *station) String() string {
+	return fmt.Sprintf("station(%.4fN:%.4fE:%.3fkm, elevation: %.1f°)", s.Lat, s.Lon, s.Alt, s.Elevation)
+}
+
+func (s *station) Station() celest.Station {
+	return celest.Station{
+		Label:     s.Label,
+		Lat:       s.Lat,
+		Lon:       s.Lon,
+		Alt:       s.Alt,
+		Elevation: s.Elevation,
+	}
+}
+
+func printPasses(w io.Writer, t *celest.Trajectory, s Settings, delay bool) (int, error) {
+	const tfmt = "2006-01-02T15:04:05.000"
+
+	ps, err := t.Passes(s.Station.Station(), s.Period.Duration, s.Interval.Duration, delay)
+	if err != nil {
+		return 0, err
+	}
+	ws := newLine(s.Print.Format)
+	for _, p := range ps {
+		if p.Label != "" {
+			ws.AppendString(p.Label, 12, linewriter.AlignLeft)
+		}
+		ws.AppendString(celest.FormatCatalog(p.Sid), 6, linewriter.AlignRight)
+		ws.AppendTime(s.Print.when(p.AOS), tfmt, linewriter.AlignLeft)
+		ws.AppendFloat(p.AzimuthAOS, 7, 2, linewriter.AlignRight|linewriter.Float)
+		ws.AppendTime(s.Print.when(p.TCA), tfmt, linewriter.AlignLeft)
+		ws.AppendFloat(p.Azimuth, 7, 2, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(p.Elevation, 6, 2, linewriter.AlignRight|linewriter.Float)
+		ws.AppendFloat(p.Range, 8, 1, linewriter.AlignRight|linewriter.Float)
+		ws.AppendTime(s.Print.when(p.LOS), tfmt, linewriter.AlignLeft)
+		ws.AppendFloat(p.AzimuthLOS, 7, 2, linewriter.AlignRight|linewriter.Float)
+		ws.AppendDuration(p.Duration().Truncate(time.Second), 8, linewriter.AlignRight|linewriter.Second)
+
+		if _, err := io.Copy(w, ws); err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+	return len(ps), nil
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/timescale"
 )
 
 type meta struct {
@@ -23,50 +24,233 @@ type meta struct {
 
 	Eclipse     int
 	EclipseTime time.Duration
+
+	// number of crossing per named area
+	Areas  map[string]int
+	active map[string]bool
+
+	saa     bool
+	eclipse bool
+}
+
+// count counts the crossing and the eclipses given the current point.
+func (m *meta) count(p *celest.Point) {
+	if m == nil {
+		return
+	}
+	if !p.Saa && m.saa {
+		m.Crossing++
+	}
+	if !p.Total && m.eclipse {
+		m.Eclipse++
+	}
+	m.saa, m.eclipse = p.Saa, p.Total
+}
+
+// cross counts the crossing of the named areas given the labels of the areas
+// crossed at the current point.
+func (m *meta) cross(labels, areas []string) {
+	if m == nil {
+		return
+	}
+	if m.Areas == nil {
+		m.Areas = make(map[string]int)
+		m.active = make(map[string]bool)
+	}
+	for _, a := range labels {
+		ok := hasLabel(areas, a)
+		if !ok && m.active[a] {
+			m.Areas[a]++
+		}
+		m.active[a] = ok
+	}
+}
+
+func hasLabel(areas []string, label string) bool {
+	for _, a := range areas {
+		if a == label {
+			return true
+		}
+	}
+	return false
 }
 
 type printer struct {
-	Format string `toml:"format"` // csv or pipe
-	Syst   string `toml:"frames"` // geodetic, geocentric, teme
-	DMS    bool   `toml:"toDMS"`  // convert to deg°min'sec'' NESW
-	Round  bool   `toml:"to360"`  //360
+	Format string `toml:"format"`    // csv, pipe, json, ndjson, xml, oem, oem-xml, kml, kmz or geojson
+	Syst   string `toml:"frames"`    // geodetic, geocentric, teme
+	DMS    bool   `toml:"toDMS"`     // convert to deg°min'sec'' NESW
+	Round  bool   `toml:"to360"`     //360
+	Time   string `toml:"timescale"` // utc, tai, tt, gps or ut1
+	Sun    bool   `toml:"sun"`       // beta angle and direction of the sun
+	Moon   bool   `toml:"moon"`      // direction of the moon and angle above the limb
+
+	labels  []string
+	scale   timescale.Scale
+	catalog bool         // rows start with the satellite number
+	events  []geoFeature // entry and exit points of the geojson output
 }
 
 func (pt printer) Print(w io.Writer, ps <-chan *celest.Result, s Settings) (*meta, error) {
+	pt.labels = s.Areas.Labels()
 	switch strings.ToLower(pt.Format) {
 	case "csv":
-		fmt.Fprintf(w, "#%s-%s (build: %s)", Program, Version, BuildTime)
-		fmt.Fprintln(w)
-		fmt.Fprintln(w, "#"+strings.Join(os.Args, " "))
-		fmt.Fprintf(w, "#trajectory duration %s", s.Period.Duration)
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "#trajectory interval %s", s.Interval.Duration)
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "#satellite identifier %d", s.Sid)
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "#bstar-drag coefficient limit %.6f", s.BStar)
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "#crossing area %s", s.Area.String())
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "#latlon system %s", s.Print.Syst)
-		fmt.Fprintln(w)
-		fmt.Fprintln(w, "#time, mjd, altitude, latitude, longitude, eclipse, saa, epoch")
-
+		if !pt.catalog {
+			pt.printHeader(w, s)
+		}
 		return pt.printCSV(w, ps)
 	case "", "pipe":
 		return pt.printPipe(w, ps)
+	case "json":
+		return pt.printJSON(w, ps, s, false)
+	case "ndjson":
+		return pt.printJSON(w, ps, s, true)
+	case "xml":
+		return pt.printXML(w, ps, s)
+	case "oem":
+		return pt.printOEM(w, ps)
+	case "oem-xml":
+		return pt.printOEMXML(w, ps)
+	case "geojson":
+		return pt.printGeoJSON(w, ps)
+	case "kml":
+		return pt.printKML(w, ps, s, false)
+	case "kmz":
+		return pt.printKML(w, ps, s, true)
 	default:
 		return nil, fmt.Errorf("unsupported format %s", pt.Format)
 	}
 }
 
+// printHeader writes the settings and the columns of the csv output.
+func (pt printer) printHeader(w io.Writer, s Settings) {
+	fmt.Fprintf(w, "#%s-%s (build: %s)", Program, Version, BuildTime)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "#"+strings.Join(os.Args, " "))
+	fmt.Fprintf(w, "#trajectory duration %s", s.Period.Duration)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "#trajectory interval %s", s.Interval.Duration)
+	fmt.Fprintln(w)
+	if !pt.catalog {
+		fmt.Fprintf(w, "#satellite identifier %s", celest.FormatCatalog(s.sat.Sid))
+		fmt.Fprintln(w)
+		if s.sat.Name != "" {
+			fmt.Fprintf(w, "#satellite name %s", s.sat.Name)
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintf(w, "#bstar-drag coefficient limit %.6f", s.BStar)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "#crossing area %s", s.Area.String())
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "#latlon system %s", s.Print.Syst)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "#time scale %s", s.Print.scale)
+	fmt.Fprintln(w)
+	for _, a := range s.Areas {
+		fmt.Fprintf(w, "#named area %s %s", a.Label, a.String())
+		fmt.Fprintln(w)
+	}
+	fmt.Fprint(w, "#")
+	if pt.catalog {
+		fmt.Fprint(w, "sid, ")
+	}
+	fmt.Fprint(w, "time, mjd, altitude, latitude, longitude, eclipse, saa, epoch, vx, vy, vz, ground, speed")
+	if pt.Sun {
+		fmt.Fprint(w, ", beta, sun x, sun y, sun z")
+	}
+	if pt.Moon {
+		fmt.Fprint(w, ", moon x, moon y, moon z, moon limb")
+	}
+	for _, a := range s.Areas.Labels() {
+		fmt.Fprint(w, ", "+a)
+	}
+	fmt.Fprintln(w)
+}
+
+// checkBodies gives an error when -sun or -moon is given with a format that can
+// not carry their values.
+func (pt printer) checkBodies() error {
+	if !pt.Sun && !pt.Moon {
+		return nil
+	}
+	switch strings.ToLower(pt.Format) {
+	case "", "pipe", "csv", "json", "ndjson", "xml":
+		return nil
+	default:
+		return fmt.Errorf("-sun and -moon are not supported by format %s", pt.Format)
+	}
+}
+
 func (pt printer) rawFormat() bool {
-	syst := strings.ToLower(pt.Syst)
-	return syst == "teme" || syst == "eci" || syst == "ecef"
+	switch strings.ToLower(pt.Syst) {
+	case "teme", "eci", "ecef", "j2000", "eme2000", "gcrf":
+		return true
+	default:
+		return false
+	}
 }
 
 func (pt printer) transform(p *celest.Point) *celest.Point {
-	return transform(p, pt.Syst)
+	return pt.retime(transform(p, pt.Syst))
+}
+
+// bodies gives the columns of the sun (beta angle and direction) and of the
+// moon (direction and angle above the limb of the earth) at p (in the TEME
+// frame). The directions are given in the frame of the velocity.
+func (pt printer) bodies(p *celest.Point) []float64 {
+	var bs []float64
+	if pt.Sun {
+		x, y, z := pt.rotate(p.When, p.SunDirection())
+		bs = append(bs, p.Beta(), x, y, z)
+	}
+	if pt.Moon {
+		x, y, z := pt.rotate(p.When, p.MoonDirection())
+		bs = append(bs, x, y, z, p.MoonLimb())
+	}
+	return bs
+}
+
+// rotate gives the vector vs of the TEME frame at w in the frame of the output:
+// the earth fixed frame unless the output is in an inertial frame.
+func (pt printer) rotate(w time.Time, vs []float64) (float64, float64, float64) {
+	p := celest.Point{When: w, Lat: vs[0], Lon: vs[1], Alt: vs[2]}
+	switch strings.ToLower(pt.Syst) {
+	case "teme", "eci":
+	case "j2000", "eme2000":
+		p = p.J2000()
+	case "gcrf":
+		p = p.GCRF()
+	default:
+		p = p.CNES()
+	}
+	return p.Lat, p.Lon, p.Alt
+}
+
+// retime gives a copy of p with its time (and julian date) in the time scale of
+// the output.
+func (pt printer) retime(p *celest.Point) *celest.Point {
+	if pt.scale == timescale.UTC {
+		return p
+	}
+	n := *p
+	n.When = pt.when(p.When)
+	n.Epoch += n.When.Sub(p.When).Hours() / 24
+	return &n
+}
+
+// when gives w (UTC) in the time scale of the output.
+func (pt printer) when(w time.Time) time.Time {
+	return timescale.Convert(w, timescale.UTC, pt.scale)
+}
+
+// prepare transforms the point in the selected frame and put its longitude in
+// the range [0:360[ if requested.
+func (pt printer) prepare(p *celest.Point) *celest.Point {
+	p = pt.transform(p)
+	if !pt.rawFormat() && pt.Round {
+		p.Lon = math.Mod(p.Lon+360, 360)
+	}
+	return p
 }
 
 func (pt printer) printCSV(w io.Writer, ps <-chan *celest.Result) (*meta, error) {
@@ -79,10 +263,10 @@ func (pt printer) printCSV(w io.Writer, ps <-chan *celest.Result) (*meta, error)
 		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
 		m.TLE++
 		m.Points += len(r.Points)
-		fmt.Fprintf(w, "#%s", r.TLE[0])
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "#%s", r.TLE[1])
-		fmt.Fprintln(w)
+		for _, row := range r.TLE {
+			fmt.Fprintf(w, "#%s", row)
+			fmt.Fprintln(w)
+		}
 
 		if err := pt.printRow(ws, r, &m); err != nil {
 			return nil, err
@@ -92,27 +276,15 @@ func (pt printer) printCSV(w io.Writer, ps <-chan *celest.Result) (*meta, error)
 }
 
 func (pt printer) printRow(ws *csv.Writer, r *celest.Result, m *meta) error {
-	var saa, eclipse *celest.Point
 	for _, p := range r.Points {
-		p = pt.transform(p)
-		if p.Saa && saa == nil {
-			saa = p
+		bs := pt.bodies(p)
+		p = pt.prepare(p)
+		m.count(p)
+		var rs []string
+		if pt.catalog {
+			rs = append(rs, celest.FormatCatalog(r.Sid))
 		}
-		if !p.Saa && saa != nil {
-			m.Crossing++
-			saa = nil
-		}
-		if p.Total && eclipse == nil {
-			eclipse = p
-		}
-		if !p.Total && eclipse != nil {
-			m.Eclipse++
-			eclipse = nil
-		}
-		if !pt.rawFormat() && pt.Round {
-			p.Lon = math.Mod(p.Lon+360, 360)
-		}
-		rs := []string{
+		rs = append(rs,
 			p.When.Format("2006-01-02T15:04:05.000000"),
 			strconv.FormatFloat(p.MJD(), 'f', -1, 64),
 			strconv.FormatFloat(p.Alt, 'f', -1, 64),
@@ -121,7 +293,19 @@ func (pt printer) printRow(ws *csv.Writer, r *celest.Result, m *meta) error {
 			formatBool(p.Total),
 			formatBool(p.Saa),
 			strconv.FormatFloat(r.Epoch, 'f', -1, 64),
+			strconv.FormatFloat(p.Vx, 'f', -1, 64),
+			strconv.FormatFloat(p.Vy, 'f', -1, 64),
+			strconv.FormatFloat(p.Vz, 'f', -1, 64),
+			strconv.FormatFloat(p.Ground, 'f', -1, 64),
+			strconv.FormatFloat(p.Speed, 'f', -1, 64),
+		)
+		for _, b := range bs {
+			rs = append(rs, strconv.FormatFloat(b, 'f', -1, 64))
+		}
+		for _, a := range pt.labels {
+			rs = append(rs, formatBool(hasLabel(p.Areas, a)))
 		}
+		m.cross(pt.labels, p.Areas)
 		if err := ws.Write(rs); err != nil {
 			return err
 		}
@@ -130,6 +314,10 @@ func (pt printer) printRow(ws *csv.Writer, r *celest.Result, m *meta) error {
 	return ws.Error()
 }
 
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
 func formatBool(b bool) string {
 	if b {
 		return "1"
@@ -140,12 +328,11 @@ func formatBool(b bool) string {
 func (pt printer) printPipe(w io.Writer, ps <-chan *celest.Result) (*meta, error) {
 	var row string
 	if !pt.rawFormat() && pt.DMS {
-		row = "%s | %.6f | %18.5f | %s | %s | %s | %s | %.6f"
+		row = "%s | %.6f | %18.5f | %s | %s | %s | %s | %.6f | %10.5f | %10.5f | %10.5f | %8.5f | %8.5f"
 	} else {
-		row = "%s | %.6f | %18.5f | %18.5f | %18.5f | %s | %s | %.6f"
+		row = "%s | %.6f | %18.5f | %18.5f | %18.5f | %s | %s | %.6f | %10.5f | %10.5f | %10.5f | %8.5f | %8.5f"
 	}
 	var m meta
-	var saa, eclipse *celest.Point
 	for r := range ps {
 		if r.Err != nil {
 			return nil, r.Err
@@ -154,32 +341,27 @@ func (pt printer) printPipe(w io.Writer, ps <-chan *celest.Result) (*meta, error
 		m.TLE++
 		m.Points += len(r.Points)
 		for _, p := range r.Points {
-			p = pt.transform(p)
-			if p.Saa && saa == nil {
-				saa = p
-			}
-			if !p.Saa && saa != nil {
-				m.Crossing++
-				saa = nil
-			}
-			if p.Total && eclipse == nil {
-				eclipse = p
-			}
-			if !p.Total && eclipse != nil {
-				m.Eclipse++
-				eclipse = nil
-			}
-			if !pt.rawFormat() && pt.Round {
-				p.Lon = math.Mod(p.Lon+360, 360)
-			}
+			bs := pt.bodies(p)
+			p = pt.prepare(p)
+			m.count(p)
 			var lat, lon interface{}
 			if !pt.rawFormat() && pt.DMS {
 				lat, lon = toDMS(p.Lat, "SN"), toDMS(p.Lon, "EW")
 			} else {
 				lat, lon = p.Lat, p.Lon
 			}
-			fmt.Fprintf(w, row, p.When.Format("2006-01-02 15:04:05.000000"), p.MJD(), p.Alt, lat, lon, formatBool(p.Total), formatBool(p.Saa), r.Epoch)
+			if pt.catalog {
+				fmt.Fprintf(w, "%6s | ", celest.FormatCatalog(r.Sid))
+			}
+			fmt.Fprintf(w, row, p.When.Format("2006-01-02 15:04:05.000000"), p.MJD(), p.Alt, lat, lon, formatBool(p.Total), formatBool(p.Saa), r.Epoch, p.Vx, p.Vy, p.Vz, p.Ground, p.Speed)
+			for _, b := range bs {
+				fmt.Fprintf(w, " | %10.5f", b)
+			}
+			for _, a := range pt.labels {
+				fmt.Fprint(w, " | "+formatBool(hasLabel(p.Areas, a)))
+			}
 			fmt.Fprintln(w)
+			m.cross(pt.labels, p.Areas)
 		}
 	}
 	return &m, nil
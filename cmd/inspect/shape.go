@@ -2,35 +2,214 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/shape"
 )
 
-var SAA = rect{
+var SAA = area{
 	North: SAALatMax,
 	South: SAALatMin,
 	East:  SAALonMax,
 	West:  SAALonMin,
 }
 
-type rect struct {
+type area struct {
+	Label string `toml:"label"`
+
 	North float64
 	South float64
 	West  float64
 	East  float64
-	Syst  string
+
+	// Centre and radius (km) of a circular area
+	Lat    float64 `toml:"latitude"`
+	Lon    float64 `toml:"longitude"`
+	Radius float64 `toml:"radius"`
+
+	// GeoJSON or WKT file with the polygon(s) of the area
+	File string `toml:"file"`
+
+	// Name of a builtin volume (saa) whose footprint depends on the altitude
+	Model string `toml:"model"`
+
+	// Altitude limits (km) of the area
+	Alt altitude `toml:"altitude"`
+
+	Syst string
+	poly shape.MultiPolygon
+}
+
+func (a *area) Contains(p celest.Point) bool {
+	x := transform(&p, a.Syst)
+	if !a.Alt.Contains(x.Alt) {
+		return false
+	}
+	if a.Model == "saa" {
+		return shape.SAA.Within(x.Lat, x.Lon, x.Alt)
+	}
+	return a.surface().Contains(x.Lat, x.Lon)
+}
+
+func (a *area) surface() shape.Surface {
+	switch {
+	case a.poly != nil:
+		return a.poly
+	case a.Radius > 0:
+		return shape.Circle{Lat: a.Lat, Lon: a.Lon, Radius: a.Radius}
+	default:
+		return shape.Rect{North: a.North, South: a.South, West: a.West, East: a.East}
+	}
+}
+
+// Outline gives the polygon(s) drawing the footprint of the area. For a model,
+// the footprint of each of its layers is given.
+func (a *area) Outline() shape.MultiPolygon {
+	switch {
+	case a.Model == "saa":
+		var mp shape.MultiPolygon
+		for _, y := range shape.SAA {
+			if r, ok := y.Surface.(shape.Rect); ok {
+				mp = append(mp, shape.Polygon{r.Ring()})
+			}
+		}
+		return mp
+	case a.poly != nil:
+		return a.poly
+	case a.Radius > 0:
+		c := shape.Circle{Lat: a.Lat, Lon: a.Lon, Radius: a.Radius}
+		return shape.MultiPolygon{{c.Ring(72)}}
+	default:
+		r := shape.Rect{North: a.North, South: a.South, West: a.West, East: a.East}
+		return shape.MultiPolygon{{r.Ring()}}
+	}
+}
+
+// Load reads the polygon(s) of the area when a file is given.
+func (a *area) Load() error {
+	if a.Model != "" && a.Model != "saa" {
+		return fmt.Errorf("unknown area model %s", a.Model)
+	}
+	if a.File == "" || a.poly != nil {
+		return nil
+	}
+	mp, err := shape.Load(a.File)
+	if err == nil {
+		a.poly = mp
+	}
+	return err
+}
+
+func (a *area) Set(s string) error {
+	a.Radius, a.Model = 0, ""
+	switch strings.Count(s, ":") {
+	case 3:
+		_, err := fmt.Sscanf(s, "%f:%f:%f:%f", &a.North, &a.East, &a.South, &a.West)
+		a.File, a.poly = "", nil
+		return err
+	case 2:
+		_, err := fmt.Sscanf(s, "%f:%f:%f", &a.Lat, &a.Lon, &a.Radius)
+		a.File, a.poly = "", nil
+		return err
+	}
+	str := strings.ToUpper(strings.TrimSpace(s))
+	if str == "SAA" {
+		a.Model, a.File, a.poly = "saa", "", nil
+		return nil
+	}
+	if strings.HasPrefix(str, "POLYGON") || strings.HasPrefix(str, "MULTIPOLYGON") {
+		mp, err := shape.ParseWKT(s)
+		if err == nil {
+			a.File, a.poly = "", mp
+		}
+		return err
+	}
+	a.File, a.poly = s, nil
+	return a.Load()
+}
+
+func (a *area) String() string {
+	var str string
+	switch {
+	case a.Model != "":
+		str = fmt.Sprintf("model(%s)", a.Model)
+	case a.File != "":
+		str = fmt.Sprintf("polygon(%s)", a.File)
+	case a.poly != nil:
+		str = fmt.Sprintf("polygon(%d)", len(a.poly))
+	case a.Radius > 0:
+		str = fmt.Sprintf("circle(%.2fN:%.2fE:%.1fkm)", a.Lat, a.Lon, a.Radius)
+	default:
+		str = fmt.Sprintf("rect(%.2fN:%.2fE:%.2fS:%.2fW)", a.North, a.East, a.South, a.West)
+	}
+	if !a.Alt.IsZero() {
+		str += " " + a.Alt.String()
+	}
+	return str
+}
+
+type zones []area
+
+// Areas gives the named areas to check during the prediction.
+func (z zones) Areas() []celest.Area {
+	as := make([]celest.Area, len(z))
+	for i := range z {
+		as[i] = celest.Area{Label: z[i].Label, Shape: &z[i]}
+	}
+	return as
+}
+
+func (z zones) Labels() []string {
+	vs := make([]string, len(z))
+	for i := range z {
+		vs[i] = z[i].Label
+	}
+	return vs
+}
+
+func (z *zones) Set(s string) error {
+	ix := strings.Index(s, "=")
+	if ix <= 0 {
+		return fmt.Errorf("invalid named area %s (LABEL=AREA)", s)
+	}
+	a := area{Label: s[:ix]}
+	if err := a.Set(s[ix+1:]); err != nil {
+		return err
+	}
+	*z = append(*z, a)
+	return nil
+}
+
+func (z *zones) String() string {
+	vs := make([]string, len(*z))
+	for i, a := range *z {
+		vs[i] = fmt.Sprintf("%s=%s", a.Label, a.String())
+	}
+	return strings.Join(vs, ", ")
+}
+
+type altitude struct {
+	Min float64 `toml:"min"`
+	Max float64 `toml:"max"`
+}
+
+func (a *altitude) Contains(alt float64) bool {
+	return shape.Range(*a).Contains(alt)
 }
 
-func (r *rect) Contains(p celest.Point) bool {
-	x := transform(&p, r.Syst)
-	return (x.Lat > r.South && x.Lat < r.North) && (x.Lon > r.West && x.Lon < r.East)
+func (a *altitude) IsZero() bool {
+	return a.Min == 0 && a.Max == 0
 }
 
-func (r *rect) Set(s string) error {
-	_, err := fmt.Sscanf(s, "%f:%f:%f:%f", &r.North, &r.East, &r.South, &r.West)
+func (a *altitude) Set(s string) error {
+	_, err := fmt.Sscanf(s, "%f:%f", &a.Min, &a.Max)
+	if err == nil && a.Max > 0 && a.Max < a.Min {
+		err = fmt.Errorf("invalid altitude range %s", s)
+	}
 	return err
 }
 
-func (r *rect) String() string {
-	return fmt.Sprintf("rect(%.2fN:%.2fE:%.2fS:%.2fW)", r.North, r.East, r.South, r.West)
+func (a *altitude) String() string {
+	return fmt.Sprintf("alt(%.1fkm:%.1fkm)", a.Min, a.Max)
 }
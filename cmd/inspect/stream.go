@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log"
+	"time"
+
+	"github.com/busoc/inspect"
+	"github.com/busoc/inspect/timescale"
+)
+
+type header struct {
+	XMLName  xml.Name `json:"-" xml:"settings"`
+	Program  string   `json:"program" xml:"program"`
+	Duration string   `json:"duration" xml:"duration"`
+	Interval string   `json:"interval" xml:"interval"`
+	Sid      string   `json:"satellite" xml:"satellite"`
+	Name     string   `json:"name,omitempty" xml:"name,omitempty"`
+	BStar    float64  `json:"bstar" xml:"bstar"`
+	Area     string   `json:"area" xml:"area"`
+	Areas    []string `json:"areas,omitempty" xml:"named,omitempty"`
+	Syst     string   `json:"frames" xml:"frames"`
+	Scale    string   `json:"timescale" xml:"timescale"`
+}
+
+func newHeader(s Settings) header {
+	h := header{
+		Program:  Program + "-" + Version,
+		Duration: s.Period.Duration.String(),
+		Interval: s.Interval.Duration.String(),
+		Sid:      celest.FormatCatalog(s.sat.Sid),
+		Name:     s.sat.Name,
+		BStar:    s.BStar,
+		Area:     s.Area.String(),
+		Syst:     s.Print.Syst,
+		Scale:    s.Print.scale.String(),
+	}
+	for _, a := range s.Areas {
+		h.Areas = append(h.Areas, a.Label+"="+a.String())
+	}
+	return h
+}
+
+type record struct {
+	XMLName  xml.Name    `json:"-" xml:"point"`
+	Sid      string      `json:"satellite,omitempty" xml:"-"`
+	Name     string      `json:"name,omitempty" xml:"-"`
+	TLE      []string    `json:"tle,omitempty" xml:"-"`
+	When     string      `json:"dtstamp" xml:"dtstamp"`
+	Scale    string      `json:"timescale,omitempty" xml:"timescale,omitempty"`
+	MJD      float64     `json:"mjd" xml:"mjd"`
+	Alt      float64     `json:"alt" xml:"alt"`
+	Lat      interface{} `json:"lat" xml:"lat"`
+	Lon      interface{} `json:"lon" xml:"lon"`
+	Eclipse  bool        `json:"eclipse" xml:"eclipse"`
+	Penumbra bool        `json:"penumbra" xml:"penumbra"`
+	Occulted float64     `json:"occulted" xml:"occulted"`
+	Saa      bool        `json:"crossing" xml:"crossing"`
+	Epoch    float64     `json:"epoch" xml:"epoch"`
+	Vx       float64     `json:"vx" xml:"vx"`
+	Vy       float64     `json:"vy" xml:"vy"`
+	Vz       float64     `json:"vz" xml:"vz"`
+	Ground   float64     `json:"ground" xml:"ground"`
+	Speed    float64     `json:"speed" xml:"speed"`
+	Areas    []string    `json:"areas,omitempty" xml:"area,omitempty"`
+	Sun      *sunRecord  `json:"sun,omitempty" xml:"sun,omitempty"`
+	Moon     *moonRecord `json:"moon,omitempty" xml:"moon,omitempty"`
+}
+
+// sunRecord gives the beta angle and the direction of the sun (-sun).
+type sunRecord struct {
+	Beta float64 `json:"beta" xml:"beta"`
+	X    float64 `json:"x" xml:"x"`
+	Y    float64 `json:"y" xml:"y"`
+	Z    float64 `json:"z" xml:"z"`
+}
+
+// moonRecord gives the direction of the moon and its angle above the limb
+// (-moon).
+type moonRecord struct {
+	X    float64 `json:"x" xml:"x"`
+	Y    float64 `json:"y" xml:"y"`
+	Z    float64 `json:"z" xml:"z"`
+	Limb float64 `json:"limb" xml:"limb"`
+}
+
+// bodyRecords gives the sun and moon fields of p (before its transformation
+// by prepare) as asked with -sun and -moon.
+func (pt printer) bodyRecords(p *celest.Point) (*sunRecord, *moonRecord) {
+	var (
+		sun  *sunRecord
+		moon *moonRecord
+	)
+	if pt.Sun {
+		x, y, z := pt.rotate(p.When, p.SunDirection())
+		sun = &sunRecord{Beta: p.Beta(), X: x, Y: y, Z: z}
+	}
+	if pt.Moon {
+		x, y, z := pt.rotate(p.When, p.MoonDirection())
+		moon = &moonRecord{X: x, Y: y, Z: z, Limb: p.MoonLimb()}
+	}
+	return sun, moon
+}
+
+func (pt printer) record(p *celest.Point, r *celest.Result) record {
+	c := record{
+		When:     pt.stamp(p.When),
+		MJD:      p.MJD(),
+		Alt:      p.Alt,
+		Lat:      p.Lat,
+		Lon:      p.Lon,
+		Eclipse:  p.Total,
+		Penumbra: p.Partial,
+		Occulted: p.Occulted,
+		Saa:      p.Saa,
+		Epoch:    r.Epoch,
+		Vx:       p.Vx,
+		Vy:       p.Vy,
+		Vz:       p.Vz,
+		Ground:   p.Ground,
+		Speed:    p.Speed,
+		Areas:    p.Areas,
+	}
+	if pt.scale != timescale.UTC {
+		c.Scale = pt.scale.String()
+	}
+	if !pt.rawFormat() && pt.DMS {
+		c.Lat, c.Lon = toDMS(p.Lat, "SN"), toDMS(p.Lon, "EW")
+	}
+	return c
+}
+
+// stamp formats w, already given in the time scale of the output. Only the UTC
+// times are marked with the Z suffix.
+func (pt printer) stamp(w time.Time) string {
+	if pt.scale == timescale.UTC {
+		return w.Format(time.RFC3339Nano)
+	}
+	return w.Format("2006-01-02T15:04:05.999999999")
+}
+
+// printJSON writes the predicted trajectory as one JSON document made of the
+// settings and of the points predicted from each TLE. When lines is true, each
+// point is written as a single JSON object per line (NDJSON) with the satellite
+// and the TLE it is predicted from.
+func (pt printer) printJSON(w io.Writer, ps <-chan *celest.Result, s Settings, lines bool) (*meta, error) {
+	var (
+		m     meta
+		first = true
+		ws    = &errWriter{Writer: bufio.NewWriter(w)}
+	)
+	if !lines {
+		ws.WriteString(`{"settings":`)
+		ws.Encode(newHeader(s))
+		ws.WriteString(`,"trajectories":[`)
+	}
+	for r := range ps {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
+		m.TLE++
+		m.Points += len(r.Points)
+		if !lines {
+			if !first {
+				ws.WriteString(",")
+			}
+			ws.WriteString(`{"tle":`)
+			ws.Encode(r.TLE)
+			ws.WriteString(`,"epoch":`)
+			ws.Encode(r.Epoch)
+			ws.WriteString(`,"when":`)
+			ws.Encode(r.When)
+			ws.WriteString(`,"points":[`)
+		}
+		for i, p := range r.Points {
+			sun, moon := pt.bodyRecords(p)
+			p = pt.prepare(p)
+			m.count(p)
+			m.cross(pt.labels, p.Areas)
+
+			c := pt.record(p, r)
+			c.Sun, c.Moon = sun, moon
+			if lines {
+				c.Sid, c.Name, c.TLE = celest.FormatCatalog(r.Sid), r.Name, r.TLE
+			} else if i > 0 {
+				ws.WriteString(",")
+			}
+			ws.Encode(c)
+			if lines {
+				ws.WriteString("\n")
+			}
+			if ws.err != nil {
+				return nil, ws.err
+			}
+		}
+		if !lines {
+			ws.WriteString("]}")
+		}
+		if err := ws.Flush(); err != nil {
+			return nil, err
+		}
+		first = false
+	}
+	if !lines {
+		ws.WriteString("]}\n")
+	}
+	if err := ws.Flush(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// errWriter keeps the first error of the writes to the underlying writer. The
+// writes following an error are ignored.
+type errWriter struct {
+	*bufio.Writer
+	err error
+}
+
+func (w *errWriter) WriteString(s string) {
+	if w.err == nil {
+		_, w.err = w.Writer.WriteString(s)
+	}
+}
+
+// Encode writes v encoded in JSON.
+func (w *errWriter) Encode(v interface{}) {
+	if w.err != nil {
+		return
+	}
+	buf, err := json.Marshal(v)
+	if err == nil {
+		_, err = w.Writer.Write(buf)
+	}
+	w.err = err
+}
+
+// Flush writes the buffered data and gives the first error of the writes.
+func (w *errWriter) Flush() error {
+	if w.err == nil {
+		w.err = w.Writer.Flush()
+	}
+	return w.err
+}
+
+// printXML writes the predicted trajectory as one XML document made of the
+// settings and of the points predicted from each TLE.
+func (pt printer) printXML(w io.Writer, ps <-chan *celest.Result, s Settings) (*meta, error) {
+	var (
+		m    meta
+		e    = xml.NewEncoder(w)
+		root = xml.StartElement{Name: xml.Name{Local: "trajectory"}}
+	)
+	e.Indent("", "  ")
+	if err := e.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := e.Encode(newHeader(s)); err != nil {
+		return nil, err
+	}
+	for r := range ps {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		log.Printf("TLE epoch: %s", r.When.Format(time.RFC1123))
+		m.TLE++
+		m.Points += len(r.Points)
+
+		el := xml.StartElement{
+			Name: xml.Name{Local: "element"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "epoch"}, Value: r.When.Format(time.RFC3339)},
+				{Name: xml.Name{Local: "jd"}, Value: formatFloat(r.Epoch)},
+			},
+		}
+		if err := e.EncodeToken(el); err != nil {
+			return nil, err
+		}
+		for _, t := range r.TLE {
+			if err := e.EncodeElement(t, xml.StartElement{Name: xml.Name{Local: "tle"}}); err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range r.Points {
+			sun, moon := pt.bodyRecords(p)
+			p = pt.prepare(p)
+			m.count(p)
+			m.cross(pt.labels, p.Areas)
+
+			c := pt.record(p, r)
+			c.Sun, c.Moon = sun, moon
+			if err := e.Encode(c); err != nil {
+				return nil, err
+			}
+		}
+		if err := e.EncodeToken(el.End()); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.EncodeToken(root.End()); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	_, err := io.WriteString(w, "\n")
+	return &m, err
+}
@@ -0,0 +1,147 @@
+package celest
+
+import (
+	"time"
+)
+
+type Conjunction struct {
+	Sid   int
+	Other int
+
+	// Time of closest approach
+	TCA time.Time
+
+	// Miss distance (kilometers) and its radial, in-track and cross-track
+	// components in the orbital frame of the first satellite
+	Distance   float64
+	Radial     float64
+	InTrack    float64
+	CrossTrack float64
+
+	// Relative velocity (kilometers/second) at TCA
+	Speed float64
+}
+
+// Conjunctions gives the close approaches between the satellite and the
+// satellite of o over the period p with a miss distance below dist
+// (kilometers, no limit when dist is not positive). The distance between both
+// satellites is sampled every s and each minimum is refined between the
+// samples around it.
+func (e Element) Conjunctions(o Element, p, s time.Duration, dist float64) ([]*Conjunction, error) {
+	from, _ := e.Range(p)
+	return e.conjunctions(o, from, p, s, dist)
+}
+
+func (e Element) conjunctions(o Element, from time.Time, p, s time.Duration, dist float64) ([]*Conjunction, error) {
+	g1, err := e.propagator()
+	if err != nil {
+		return nil, err
+	}
+	defer g1.Close()
+	g2, err := o.propagator()
+	if err != nil {
+		return nil, err
+	}
+	defer g2.Close()
+
+	// minutes between the epochs of both elements
+	offset := (g1.els.GetJdsatepoch() - g2.els.GetJdsatepoch()) + (g1.els.GetJdsatepochF() - g2.els.GetJdsatepochF())
+	offset *= minPerDays
+
+	state := func(w float64) ([]float64, []float64, []float64, []float64, error) {
+		p1, err := g1.At(w)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		p2, err := g2.At(w + offset)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		r := []float64{p1.Lat, p1.Lon, p1.Alt}
+		v := []float64{p1.Vx, p1.Vy, p1.Vz}
+		dr := []float64{p2.Lat - p1.Lat, p2.Lon - p1.Lon, p2.Alt - p1.Alt}
+		dv := []float64{p2.Vx - p1.Vx, p2.Vy - p1.Vy, p2.Vz - p1.Vz}
+		return r, v, dr, dv, nil
+	}
+	distance := func(w float64) (float64, error) {
+		_, _, dr, _, err := state(w)
+		return norm(dr), err
+	}
+	build := func(w float64) (*Conjunction, error) {
+		r, v, dr, dv, err := state(w)
+		if err != nil {
+			return nil, err
+		}
+		c := Conjunction{
+			Sid:      e.Sid,
+			Other:    o.Sid,
+			TCA:      g1.Time(w),
+			Distance: norm(dr),
+			Speed:    norm(dv),
+		}
+		c.Radial, c.InTrack, c.CrossTrack = OrbitalFrame(r, v, dr)
+		return &c, nil
+	}
+
+	delta := s.Seconds() / time.Minute.Seconds()
+	when := from.Sub(e.When).Seconds() / time.Minute.Seconds()
+
+	// one more sample on both sides of the period to only keep the minima
+	// surrounded by two samples
+	var ds []float64
+	for elapsed := -s; elapsed <= p+s; elapsed += s {
+		d, err := distance(when + elapsed.Minutes())
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+
+	var cs []*Conjunction
+	for i := 1; i < len(ds)-1; i++ {
+		if ds[i-1] < ds[i] || ds[i+1] <= ds[i] {
+			continue
+		}
+		w := when + float64(i-1)*delta
+		tca, miss, err := minimize(distance, w-delta, w+delta)
+		if err != nil {
+			return cs, err
+		}
+		if dist > 0 && miss > dist {
+			continue
+		}
+		c, err := build(tca)
+		if err != nil {
+			return cs, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// OrbitalFrame gives the radial, in-track and cross-track components of vs in
+// the orbital frame of the satellite at the position pos with the velocity vel
+// (inertial frame).
+func OrbitalFrame(pos, vel, vs []float64) (float64, float64, float64) {
+	radial := unit(pos)
+	normal := unit(cross(pos, vel))
+	track := cross(normal, radial)
+	return dot(vs, radial), dot(vs, track), dot(vs, normal)
+}
+
+func dot(a, b []float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a, b []float64) []float64 {
+	return []float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func unit(vs []float64) []float64 {
+	n := norm(vs)
+	return []float64{vs[0] / n, vs[1] / n, vs[2] / n}
+}
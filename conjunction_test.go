@@ -0,0 +1,39 @@
+package celest
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleElement_Conjunctions() {
+	e, err := NewElement(
+		"1 25544U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9995",
+		"2 25544  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139693",
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// same orbit with its ascending node 0.5° further east
+	o, err := NewElement(
+		"1 25545U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9996",
+		"2 25545  51.6420  60.6332 0004268 356.0118  61.1534 15.53880871139699",
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cs, err := e.Conjunctions(*o, 3*time.Hour, time.Minute, 0)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, c := range cs {
+		fmt.Printf("%s: %.3f km (%.3f, %.3f, %.3f)\n", c.TCA.Format("15:04:05"), c.Distance, c.Radial, c.InTrack, c.CrossTrack)
+	}
+	// Output:
+	// 08:45:46: 36.715 km (-0.099, 36.715, 0.113)
+	// 09:32:06: 36.776 km (-0.100, 36.776, -0.138)
+	// 10:18:23: 36.715 km (-0.099, 36.715, 0.113)
+	// 11:04:43: 36.776 km (-0.100, 36.776, -0.138)
+}
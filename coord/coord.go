@@ -2,7 +2,6 @@ package coord
 
 import (
 	"math"
-	"time"
 )
 
 const Tolerance = 0.00000001
@@ -18,10 +17,6 @@ const (
 	earthRadius = 6378.1363
 )
 
-func Teme2ECEF(w time.Time, x, y, z float64) (float64, float64, float64) {
-	return 0, 0, 0
-}
-
 func GeodeticFromECEF(x, y, z float64) (float64, float64, float64) {
 	lat, lon, alt := ecef2Geodetic(x, y, z)
 	return lat * rad2deg, lon * rad2deg, alt
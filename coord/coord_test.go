@@ -2,6 +2,7 @@ package coord
 
 import (
 	"fmt"
+	"time"
 )
 
 func ExampleGeodeticFromECEF() {
@@ -27,3 +28,36 @@ func ExampleGeocentricFromECEF() {
 	// Output:
 	// lat: 34.173429°, lon: 46.4464°
 }
+
+func ExampleDistance() {
+	lat0, lon0 := 50.8503, 4.3517
+	lat1, lon1 := 48.8566, 2.3522
+	fmt.Printf("distance: %.3f km", Distance(lat0, lon0, lat1, lon1))
+	// Output:
+	// distance: 264.268 km
+}
+
+func ExampleTeme2ITRF() {
+	w := time.Date(2004, 4, 6, 7, 51, 28, 386009000, time.UTC)
+	eop := EOP{X: -0.140682, Y: 0.333309, DUT1: -0.4399619, LOD: 0.0015563}
+	x, y, z := Teme2ITRF(w, eop, 5094.18016210, 6127.64465950, 6380.34453270)
+	fmt.Printf("x: %.3f, y: %.3f, z: %.3f", x, y, z)
+	fmt.Println()
+	vx, vy, vz := TemeVelocity2ITRF(w, eop, 5094.18016210, 6127.64465950, 6380.34453270, -4.746131487, 0.785818041, 5.531931288)
+	fmt.Printf("vx: %.6f, vy: %.6f, vz: %.6f", vx, vy, vz)
+	// Output:
+	// x: -1033.479, y: 7901.295, z: 6380.357
+	// vx: -3.225637, vy: -2.872451, vz: 5.531924
+}
+
+func ExampleTeme2J2000() {
+	tt := time.Date(2004, 4, 6, 7, 52, 32, 570009000, time.UTC)
+	x, y, z := Teme2J2000(tt, 5094.18016210, 6127.64465950, 6380.34453270)
+	fmt.Printf("x: %.3f, y: %.3f, z: %.3f", x, y, z)
+	fmt.Println()
+	vx, vy, vz := Teme2J2000(tt, -4.746131487, 0.785818041, 5.531931288)
+	fmt.Printf("vx: %.6f, vy: %.6f, vz: %.6f", vx, vy, vz)
+	// Output:
+	// x: 5102.510, y: 6123.011, z: 6378.136
+	// vx: -4.743220, vy: 0.790537, vz: 5.533756
+}
@@ -0,0 +1,77 @@
+package coord
+
+import (
+	"math"
+)
+
+// Haversine gives the great circle distance (km) between two points given by
+// their latitude and longitude (degrees) on a sphere of mean earth radius.
+func Haversine(lat0, lon0, lat1, lon1 float64) float64 {
+	const radius = (2*earthRadius + earthRadius*(1-flattening)) / 3
+
+	lat0, lat1 = lat0*deg2rad, lat1*deg2rad
+	dlat := lat1 - lat0
+	dlon := (lon1 - lon0) * deg2rad
+
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat0)*math.Cos(lat1)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * radius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Destination gives the latitude and longitude (degrees) of the point reached
+// when travelling the given distance (km) from a point along the great circle
+// with the given initial bearing (degrees).
+func Destination(lat, lon, bearing, dist float64) (float64, float64) {
+	const radius = (2*earthRadius + earthRadius*(1-flattening)) / 3
+
+	lat, lon, bearing = lat*deg2rad, lon*deg2rad, bearing*deg2rad
+	delta := dist / radius
+
+	x := math.Asin(math.Sin(lat)*math.Cos(delta) + math.Cos(lat)*math.Sin(delta)*math.Cos(bearing))
+	y := lon + math.Atan2(math.Sin(bearing)*math.Sin(delta)*math.Cos(lat), math.Cos(delta)-math.Sin(lat)*math.Sin(x))
+	return x / deg2rad, math.Remainder(y/deg2rad, 360)
+}
+
+// Distance gives the geodesic distance (km) between two points given by their
+// geodetic latitude and longitude (degrees) on the ellipsoid (Vincenty inverse
+// formula). It falls back to Haversine for nearly antipodal points for which
+// the formula does not converge.
+func Distance(lat0, lon0, lat1, lon1 float64) float64 {
+	const (
+		minor = earthRadius * (1 - flattening)
+		limit = 200
+	)
+	u0 := math.Atan((1 - flattening) * math.Tan(lat0*deg2rad))
+	u1 := math.Atan((1 - flattening) * math.Tan(lat1*deg2rad))
+	su0, cu0 := math.Sin(u0), math.Cos(u0)
+	su1, cu1 := math.Sin(u1), math.Cos(u1)
+
+	delta := (lon1 - lon0) * deg2rad
+	lambda := delta
+	for i := 0; i < limit; i++ {
+		sl, cl := math.Sin(lambda), math.Cos(lambda)
+		ss := math.Sqrt((cu1*sl)*(cu1*sl) + (cu0*su1-su0*cu1*cl)*(cu0*su1-su0*cu1*cl))
+		if ss == 0 {
+			return 0
+		}
+		cs := su0*su1 + cu0*cu1*cl
+		sigma := math.Atan2(ss, cs)
+		sa := cu0 * cu1 * sl / ss
+		ca := 1 - sa*sa
+		c2m := cs
+		if ca != 0 {
+			c2m = cs - 2*su0*su1/ca
+		}
+		c := flattening / 16 * ca * (4 + flattening*(4-3*ca))
+		prev := lambda
+		lambda = delta + (1-c)*flattening*sa*(sigma+c*ss*(c2m+c*cs*(-1+2*c2m*c2m)))
+		if math.Abs(lambda-prev) > Tolerance*Tolerance {
+			continue
+		}
+		u := ca * (earthRadius*earthRadius - minor*minor) / (minor * minor)
+		a := 1 + u/16384*(4096+u*(-768+u*(320-175*u)))
+		b := u / 1024 * (256 + u*(-128+u*(74-47*u)))
+		ds := b * ss * (c2m + b/4*(cs*(-1+2*c2m*c2m)-b/6*c2m*(-3+4*ss*ss)*(-3+4*c2m*c2m)))
+		return minor * a * (sigma - ds)
+	}
+	return Haversine(lat0, lon0, lat1, lon1)
+}
@@ -0,0 +1,257 @@
+package coord
+
+import (
+	"math"
+	"time"
+)
+
+// Teme2J2000 transforms the position (or velocity) x, y, z from the TEME frame
+// to the mean equator and equinox of J2000 (EME2000) at tt (terrestrial time)
+// with the IAU-76 precession and the IAU-80 nutation.
+func Teme2J2000(tt time.Time, x, y, z float64) (float64, float64, float64) {
+	return teme2FK5(tt, 0, 0).apply(x, y, z)
+}
+
+// J20002Teme is the inverse of Teme2J2000.
+func J20002Teme(tt time.Time, x, y, z float64) (float64, float64, float64) {
+	return teme2FK5(tt, 0, 0).transpose().apply(x, y, z)
+}
+
+// Teme2GCRF transforms the position (or velocity) x, y, z from the TEME frame
+// to the GCRF at tt (terrestrial time). The GCRF is given by the IAU-76/80
+// model corrected by the celestial pole offsets (DPsi and DEps) of eop. It is
+// J2000 when the offsets are not known.
+func Teme2GCRF(tt time.Time, eop EOP, x, y, z float64) (float64, float64, float64) {
+	return teme2FK5(tt, eop.DPsi*arcsec2rad, eop.DEps*arcsec2rad).apply(x, y, z)
+}
+
+// GCRF2Teme is the inverse of Teme2GCRF.
+func GCRF2Teme(tt time.Time, eop EOP, x, y, z float64) (float64, float64, float64) {
+	return teme2FK5(tt, eop.DPsi*arcsec2rad, eop.DEps*arcsec2rad).transpose().apply(x, y, z)
+}
+
+// teme2FK5 gives the rotation from TEME to J2000: the equation of the
+// equinoxes (without its kinematic terms) gives the true of date frame, then
+// the nutation gives the mean of date frame and the precession J2000.
+func teme2FK5(tt time.Time, dpsi, deps float64) matrix {
+	t := Centuries(tt)
+
+	eps := obliquity(t)
+	psi, de := nutation(t)
+	psi, de = psi+dpsi, de+deps
+
+	eqe := rot3(-psi * math.Cos(eps))
+	nut := rot1(-eps - de).mul(rot3(-psi)).mul(rot1(eps))
+	pre := precession(t)
+
+	return pre.transpose().mul(nut.transpose()).mul(eqe)
+}
+
+// Nutation gives the nutation in longitude and in obliquity of the IAU-80
+// theory and the mean obliquity of the ecliptic (radians) at tt (terrestrial
+// time).
+func Nutation(tt time.Time) (float64, float64, float64) {
+	t := Centuries(tt)
+	psi, eps := nutation(t)
+	return psi, eps, obliquity(t)
+}
+
+// obliquity gives the mean obliquity of the ecliptic (radians) at t (julian
+// centuries of TT since J2000).
+func obliquity(t float64) float64 {
+	return (84381.448 + (-46.8150+(-0.00059+0.001813*t)*t)*t) * arcsec2rad
+}
+
+// precession gives the IAU-76 precession matrix from J2000 to the mean of date
+// at t (julian centuries of TT since J2000).
+func precession(t float64) matrix {
+	zeta := (2306.2181 + (0.30188+0.017998*t)*t) * t * arcsec2rad
+	theta := (2004.3109 + (-0.42665-0.041833*t)*t) * t * arcsec2rad
+	z := (2306.2181 + (1.09468+0.018203*t)*t) * t * arcsec2rad
+
+	return rot3(-z).mul(rot2(theta)).mul(rot3(-zeta))
+}
+
+// nutation gives the nutation in longitude and in obliquity (radians) of the
+// IAU-80 theory at t (julian centuries of TT since J2000).
+func nutation(t float64) (float64, float64) {
+	const turn = 2 * math.Pi
+	var (
+		l  = (485866.733+(715922.633+(31.310+0.064*t)*t)*t)*arcsec2rad + math.Mod(1325*t, 1)*turn
+		lp = (1287099.804+(1292581.224+(-0.577-0.012*t)*t)*t)*arcsec2rad + math.Mod(99*t, 1)*turn
+		f  = (335778.877+(295263.137+(-13.257+0.011*t)*t)*t)*arcsec2rad + math.Mod(1342*t, 1)*turn
+		d  = (1072261.307+(1105601.328+(-6.891+0.019*t)*t)*t)*arcsec2rad + math.Mod(1236*t, 1)*turn
+		om = (450160.280+(-482890.539+(7.455+0.008*t)*t)*t)*arcsec2rad + math.Mod(-5*t, 1)*turn
+	)
+	var dp, de float64
+	for i := len(nut80) - 1; i >= 0; i-- {
+		n := nut80[i]
+		arg := n.L*l + n.Lp*lp + n.F*f + n.D*d + n.Om*om
+		dp += (n.S + n.St*t) * math.Sin(arg)
+		de += (n.C + n.Ct*t) * math.Cos(arg)
+	}
+	// coefficients are given in 0.1 milliarcseconds
+	return dp * arcsec2rad / 1e4, de * arcsec2rad / 1e4
+}
+
+type matrix [3][3]float64
+
+func (m matrix) mul(o matrix) matrix {
+	var r matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r[i][j] += m[i][k] * o[k][j]
+			}
+		}
+	}
+	return r
+}
+
+func (m matrix) transpose() matrix {
+	var r matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+func (m matrix) apply(x, y, z float64) (float64, float64, float64) {
+	return m[0][0]*x + m[0][1]*y + m[0][2]*z,
+		m[1][0]*x + m[1][1]*y + m[1][2]*z,
+		m[2][0]*x + m[2][1]*y + m[2][2]*z
+}
+
+// rot1, rot2 and rot3 give the rotation of the axes by a (radians) around x, y
+// and z.
+func rot1(a float64) matrix {
+	c, s := math.Cos(a), math.Sin(a)
+	return matrix{{1, 0, 0}, {0, c, s}, {0, -s, c}}
+}
+
+func rot2(a float64) matrix {
+	c, s := math.Cos(a), math.Sin(a)
+	return matrix{{c, 0, -s}, {0, 1, 0}, {s, 0, c}}
+}
+
+func rot3(a float64) matrix {
+	c, s := math.Cos(a), math.Sin(a)
+	return matrix{{c, s, 0}, {-s, c, 0}, {0, 0, 1}}
+}
+
+// nut80 gives the 106 terms of the IAU-80 nutation: the multipliers of the
+// fundamental arguments and the coefficients (0.1 milliarcseconds) of the
+// longitude and of the obliquity.
+var nut80 = []struct {
+	L, Lp, F, D, Om float64
+	S, St, C, Ct    float64
+}{
+	{0, 0, 0, 0, 1, -171996, -174.2, 92025, 8.9},
+	{0, 0, 0, 0, 2, 2062, 0.2, -895, 0.5},
+	{-2, 0, 2, 0, 1, 46, 0, -24, 0},
+	{2, 0, -2, 0, 0, 11, 0, 0, 0},
+	{-2, 0, 2, 0, 2, -3, 0, 1, 0},
+	{1, -1, 0, -1, 0, -3, 0, 0, 0},
+	{0, -2, 2, -2, 1, -2, 0, 1, 0},
+	{2, 0, -2, 0, 1, 1, 0, 0, 0},
+	{0, 0, 2, -2, 2, -13187, -1.6, 5736, -3.1},
+	{0, 1, 0, 0, 0, 1426, -3.4, 54, -0.1},
+	{0, 1, 2, -2, 2, -517, 1.2, 224, -0.6},
+	{0, -1, 2, -2, 2, 217, -0.5, -95, 0.3},
+	{0, 0, 2, -2, 1, 129, 0.1, -70, 0},
+	{2, 0, 0, -2, 0, 48, 0, 1, 0},
+	{0, 0, 2, -2, 0, -22, 0, 0, 0},
+	{0, 2, 0, 0, 0, 17, -0.1, 0, 0},
+	{0, 1, 0, 0, 1, -15, 0, 9, 0},
+	{0, 2, 2, -2, 2, -16, 0.1, 7, 0},
+	{0, -1, 0, 0, 1, -12, 0, 6, 0},
+	{-2, 0, 0, 2, 1, -6, 0, 3, 0},
+	{0, -1, 2, -2, 1, -5, 0, 3, 0},
+	{2, 0, 0, -2, 1, 4, 0, -2, 0},
+	{0, 1, 2, -2, 1, 4, 0, -2, 0},
+	{1, 0, 0, -1, 0, -4, 0, 0, 0},
+	{2, 1, 0, -2, 0, 1, 0, 0, 0},
+	{0, 0, -2, 2, 1, 1, 0, 0, 0},
+	{0, 1, -2, 2, 0, -1, 0, 0, 0},
+	{0, 1, 0, 0, 2, 1, 0, 0, 0},
+	{-1, 0, 0, 1, 1, 1, 0, 0, 0},
+	{0, 1, 2, -2, 0, -1, 0, 0, 0},
+	{0, 0, 2, 0, 2, -2274, -0.2, 977, -0.5},
+	{1, 0, 0, 0, 0, 712, 0.1, -7, 0},
+	{0, 0, 2, 0, 1, -386, -0.4, 200, 0},
+	{1, 0, 2, 0, 2, -301, 0, 129, -0.1},
+	{1, 0, 0, -2, 0, -158, 0, -1, 0},
+	{-1, 0, 2, 0, 2, 123, 0, -53, 0},
+	{0, 0, 0, 2, 0, 63, 0, -2, 0},
+	{1, 0, 0, 0, 1, 63, 0.1, -33, 0},
+	{-1, 0, 0, 0, 1, -58, -0.1, 32, 0},
+	{-1, 0, 2, 2, 2, -59, 0, 26, 0},
+	{1, 0, 2, 0, 1, -51, 0, 27, 0},
+	{0, 0, 2, 2, 2, -38, 0, 16, 0},
+	{2, 0, 0, 0, 0, 29, 0, -1, 0},
+	{1, 0, 2, -2, 2, 29, 0, -12, 0},
+	{2, 0, 2, 0, 2, -31, 0, 13, 0},
+	{0, 0, 2, 0, 0, 26, 0, -1, 0},
+	{-1, 0, 2, 0, 1, 21, 0, -10, 0},
+	{-1, 0, 0, 2, 1, 16, 0, -8, 0},
+	{1, 0, 0, -2, 1, -13, 0, 7, 0},
+	{-1, 0, 2, 2, 1, -10, 0, 5, 0},
+	{1, 1, 0, -2, 0, -7, 0, 0, 0},
+	{0, 1, 2, 0, 2, 7, 0, -3, 0},
+	{0, -1, 2, 0, 2, -7, 0, 3, 0},
+	{1, 0, 2, 2, 2, -8, 0, 3, 0},
+	{1, 0, 0, 2, 0, 6, 0, 0, 0},
+	{2, 0, 2, -2, 2, 6, 0, -3, 0},
+	{0, 0, 0, 2, 1, -6, 0, 3, 0},
+	{0, 0, 2, 2, 1, -7, 0, 3, 0},
+	{1, 0, 2, -2, 1, 6, 0, -3, 0},
+	{0, 0, 0, -2, 1, -5, 0, 3, 0},
+	{1, -1, 0, 0, 0, 5, 0, 0, 0},
+	{2, 0, 2, 0, 1, -5, 0, 3, 0},
+	{0, 1, 0, -2, 0, -4, 0, 0, 0},
+	{1, 0, -2, 0, 0, 4, 0, 0, 0},
+	{0, 0, 0, 1, 0, -4, 0, 0, 0},
+	{1, 1, 0, 0, 0, -3, 0, 0, 0},
+	{1, 0, 2, 0, 0, 3, 0, 0, 0},
+	{1, -1, 2, 0, 2, -3, 0, 1, 0},
+	{-1, -1, 2, 2, 2, -3, 0, 1, 0},
+	{-2, 0, 0, 0, 1, -2, 0, 1, 0},
+	{3, 0, 2, 0, 2, -3, 0, 1, 0},
+	{0, -1, 2, 2, 2, -3, 0, 1, 0},
+	{1, 1, 2, 0, 2, 2, 0, -1, 0},
+	{-1, 0, 2, -2, 1, -2, 0, 1, 0},
+	{2, 0, 0, 0, 1, 2, 0, -1, 0},
+	{1, 0, 0, 0, 2, -2, 0, 1, 0},
+	{3, 0, 0, 0, 0, 2, 0, 0, 0},
+	{0, 0, 2, 1, 2, 2, 0, -1, 0},
+	{-1, 0, 0, 0, 2, 1, 0, -1, 0},
+	{1, 0, 0, -4, 0, -1, 0, 0, 0},
+	{-2, 0, 2, 2, 2, 1, 0, -1, 0},
+	{-1, 0, 2, 4, 2, -2, 0, 1, 0},
+	{2, 0, 0, -4, 0, -1, 0, 0, 0},
+	{1, 1, 2, -2, 2, 1, 0, -1, 0},
+	{1, 0, 2, 2, 1, -1, 0, 1, 0},
+	{-2, 0, 2, 4, 2, -1, 0, 1, 0},
+	{-1, 0, 4, 0, 2, 1, 0, 0, 0},
+	{1, -1, 0, -2, 0, 1, 0, 0, 0},
+	{2, 0, 2, -2, 1, 1, 0, -1, 0},
+	{2, 0, 2, 2, 2, -1, 0, 0, 0},
+	{1, 0, 0, 2, 1, -1, 0, 0, 0},
+	{0, 0, 4, -2, 2, 1, 0, 0, 0},
+	{3, 0, 2, -2, 2, 1, 0, 0, 0},
+	{1, 0, 2, -2, 0, -1, 0, 0, 0},
+	{0, 1, 2, 0, 1, 1, 0, 0, 0},
+	{-1, -1, 0, 2, 1, 1, 0, 0, 0},
+	{0, 0, -2, 0, 1, -1, 0, 0, 0},
+	{0, 0, 2, -1, 2, -1, 0, 0, 0},
+	{0, 1, 0, 2, 0, -1, 0, 0, 0},
+	{1, 0, -2, -2, 0, -1, 0, 0, 0},
+	{0, -1, 2, 0, 1, -1, 0, 0, 0},
+	{1, 1, 0, -2, 1, -1, 0, 0, 0},
+	{1, 0, -2, 2, 0, -1, 0, 0, 0},
+	{2, 0, 0, 2, 0, 1, 0, 0, 0},
+	{0, 0, 2, 4, 2, -1, 0, 0, 0},
+	{0, 1, 0, 1, 0, 1, 0, 0, 0},
+}
@@ -0,0 +1,121 @@
+package coord
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// earthRotation is the nominal rotation rate of the earth (radians per
+	// second)
+	earthRotation = 7.292115146706979e-5
+
+	arcsec2rad = deg2rad / 3600
+	unixJD     = 2440587.5
+)
+
+const (
+	// SecPerDay is the number of seconds of a day.
+	SecPerDay = 86400.0
+
+	// J2000 is the julian date of the epoch J2000 (2000-01-01T12:00:00 TT).
+	J2000 = 2451545.0
+
+	// DeltaMJD is the difference between a julian date and a modified julian
+	// date.
+	DeltaMJD = 2400000.5
+)
+
+// EOP gives the earth orientation parameters used to transform a position from
+// the TEME frame to the ITRF or to the GCRF: the coordinates of the pole
+// (arcseconds), the difference between UT1 and UTC and the excess of the length
+// of day (seconds) and the celestial pole offsets of the IAU-80 nutation
+// (arcseconds). The zero value ignores the polar motion and uses UTC as UT1.
+type EOP struct {
+	X    float64
+	Y    float64
+	DUT1 float64
+	LOD  float64
+	DPsi float64
+	DEps float64
+}
+
+// JulianDate gives the julian date of w.
+func JulianDate(w time.Time) float64 {
+	days := float64(w.Unix()) / SecPerDay
+	return unixJD + days + float64(w.Nanosecond())/(SecPerDay*1e9)
+}
+
+// FromJulianDate gives the time of the julian date jd.
+func FromJulianDate(jd float64) time.Time {
+	sec := (jd - unixJD) * SecPerDay
+	return time.Unix(0, int64(math.Round(sec*1e9))).UTC()
+}
+
+// Centuries gives the julian centuries since J2000 of tt.
+func Centuries(tt time.Time) float64 {
+	return (JulianDate(tt) - J2000) / 36525
+}
+
+// GMST gives the Greenwich mean sidereal time (radians) at the julian date
+// jdut1 (UT1) with the IAU-82 model.
+func GMST(jdut1 float64) float64 {
+	t := (jdut1 - J2000) / 36525
+	sec := -6.2e-6*t*t*t + 0.093104*t*t + (876600*3600+8640184.812866)*t + 67310.54841
+
+	gmst := math.Mod(sec*deg2rad/240, 2*math.Pi)
+	if gmst < 0 {
+		gmst += 2 * math.Pi
+	}
+	return gmst
+}
+
+// Teme2ECEF transforms the position x, y, z from the TEME frame to the earth
+// fixed frame at w without polar motion and with UT1 equal to UTC.
+func Teme2ECEF(w time.Time, x, y, z float64) (float64, float64, float64) {
+	return Teme2ITRF(w, EOP{}, x, y, z)
+}
+
+// Teme2ITRF transforms the position x, y, z from the TEME frame to the ITRF at
+// w through the pseudo earth fixed frame (PEF).
+func Teme2ITRF(w time.Time, eop EOP, x, y, z float64) (float64, float64, float64) {
+	x, y, z = teme2PEF(w, eop, x, y, z)
+	return pef2ITRF(eop, x, y, z)
+}
+
+// TemeVelocity2ITRF transforms the velocity vx, vy, vz (per second) of the
+// position x, y, z from the TEME frame to the ITRF at w.
+func TemeVelocity2ITRF(w time.Time, eop EOP, x, y, z, vx, vy, vz float64) (float64, float64, float64) {
+	x, y, z = teme2PEF(w, eop, x, y, z)
+	vx, vy, vz = teme2PEF(w, eop, vx, vy, vz)
+
+	omega := earthRotation * (1 - eop.LOD/SecPerDay)
+	vx, vy = vx+omega*y, vy-omega*x
+	return pef2ITRF(eop, vx, vy, vz)
+}
+
+// teme2PEF rotates x, y, z by the apparent sidereal time: the IAU-82 GMST with
+// the kinematic terms of the equation of the equinoxes (after 1997).
+func teme2PEF(w time.Time, eop EOP, x, y, z float64) (float64, float64, float64) {
+	jdut1 := JulianDate(w) + eop.DUT1/SecPerDay
+	gst := GMST(jdut1)
+	if jdut1 > 2450449.5 {
+		t := (jdut1 - J2000) / 36525
+		omega := (125.04452222 + (-6962890.5390*t+7.455*t*t+0.008*t*t*t)/3600) * deg2rad
+		gst += (0.00264*math.Sin(omega) + 0.000063*math.Sin(2*omega)) * arcsec2rad
+	}
+	cos, sin := math.Cos(gst), math.Sin(gst)
+	return cos*x + sin*y, -sin*x + cos*y, z
+}
+
+// pef2ITRF applies the polar motion to x, y, z.
+func pef2ITRF(eop EOP, x, y, z float64) (float64, float64, float64) {
+	if eop.X == 0 && eop.Y == 0 {
+		return x, y, z
+	}
+	xp, yp := eop.X*arcsec2rad, eop.Y*arcsec2rad
+	cx, sx := math.Cos(xp), math.Sin(xp)
+	cy, sy := math.Cos(yp), math.Sin(yp)
+
+	return cx*x + sx*sy*y + sx*cy*z, cy*y - sy*z, -sx*x + cx*sy*y + cx*cy*z
+}
@@ -0,0 +1,105 @@
+package celest
+
+import (
+	"time"
+)
+
+type Eclipse struct {
+	Sid int
+
+	// Entry and exit time of the penumbra and of the umbra. The umbra times are
+	// zero when the satellite only crosses the penumbra.
+	PenumbraStarts time.Time
+	UmbraStarts    time.Time
+	UmbraEnds      time.Time
+	PenumbraEnds   time.Time
+
+	// Maximum percentage of the solar disk occulted by the earth
+	Occulted float64
+
+	// The satellite was already in the shadow at the start of the period or
+	// was still in it at the end (see Event).
+	StartsBefore bool
+	EndsAfter    bool
+}
+
+// Duration gives the time spent by the satellite in the umbra.
+func (e Eclipse) Duration() time.Duration {
+	return e.UmbraEnds.Sub(e.UmbraStarts)
+}
+
+// Penumbra gives the time spent by the satellite in the shadow of the earth
+// (umbra included).
+func (e Eclipse) Penumbra() time.Duration {
+	return e.PenumbraEnds.Sub(e.PenumbraStarts)
+}
+
+// Eclipses gives the umbra and penumbra entry and exit time of the satellite
+// over the period p. The points of the trajectory are sampled every s and the
+// times are refined between two samples. The eclipses truncated by the period
+// are marked.
+func (e Element) Eclipses(p, s time.Duration) ([]*Eclipse, error) {
+	g, err := e.propagator()
+	if err != nil {
+		return nil, err
+	}
+	defer g.Close()
+
+	status := func(w float64) (bool, bool, float64, error) {
+		pt, err := g.At(w)
+		if err != nil {
+			return false, false, 0, err
+		}
+		ps := []float64{pt.Lat * 1000, pt.Lon * 1000, pt.Alt * 1000}
+		fes, pes, ocs := eclipseStatus([][]float64{ps}, []float64{pt.Epoch})
+		return fes[0], pes[0], ocs[0], nil
+	}
+	shadow := func(w float64) (bool, error) {
+		total, partial, _, err := status(w)
+		return total || partial, err
+	}
+	umbra := func(w float64) (bool, error) {
+		total, _, _, err := status(w)
+		return total, err
+	}
+	occulted := func(w float64) (float64, error) {
+		_, _, oc, err := status(w)
+		return -oc, err
+	}
+
+	ps, err := e.windows(p, s, shadow)
+	if err != nil {
+		return nil, err
+	}
+	us, err := e.windows(p, s, umbra)
+	if err != nil {
+		return nil, err
+	}
+	es := make([]*Eclipse, 0, len(ps))
+	for _, w := range ps {
+		x := Eclipse{
+			Sid:            e.Sid,
+			PenumbraStarts: g.Time(w.Starts),
+			PenumbraEnds:   g.Time(w.Ends),
+			StartsBefore:   w.StartsBefore,
+			EndsAfter:      w.EndsAfter,
+		}
+		for _, u := range us {
+			if u.Starts >= w.Starts && u.Ends <= w.Ends {
+				x.UmbraStarts = g.Time(u.Starts)
+				x.UmbraEnds = g.Time(u.Ends)
+				x.Occulted = 100
+				break
+			}
+		}
+		if x.UmbraStarts.IsZero() {
+			_, oc, err := minimize(occulted, w.Starts, w.Ends)
+			if err != nil {
+				return es, err
+			}
+			x.Occulted = -oc * 100
+		}
+		es = append(es, &x)
+	}
+	return es, nil
+}
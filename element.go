@@ -7,14 +7,12 @@ import (
 
 	"github.com/busoc/inspect/coord"
 	"github.com/busoc/inspect/sgp"
-)
-
-const (
-	row1 = "%1d %5d%1s %8s %2d%12f %10f %6f%2d %6f%2d %1d %5s"
-	row2 = "%d %5d %8f %8f %7f %8f %8f %10f%5d%1s"
+	"github.com/busoc/inspect/timescale"
 )
 
 type Result struct {
+	Sid    int
+	Name   string
 	Err    error
 	TLE    []string
 	When   time.Time
@@ -31,11 +29,26 @@ type Point struct {
 	Lon float64 `json:"lon" xml:"lon"`
 	Alt float64 `json:"alt" xml:"alt"`
 
+	// Satellite velocity (km/s)
+	Vx float64 `json:"vx" xml:"vx"`
+	Vy float64 `json:"vy" xml:"vy"`
+	Vz float64 `json:"vz" xml:"vz"`
+
+	// Inertial and ground speed (km/s)
+	Speed  float64 `json:"speed" xml:"speed"`
+	Ground float64 `json:"ground" xml:"ground"`
+
 	// SAA and Eclipse crossing
 	Saa     bool `json:"crossing" xml:"crossing"`
-	Partial bool `json:"-" xml:"-"`
+	Partial bool `json:"penumbra" xml:"penumbra"`
 	Total   bool `json:"eclipse" xml:"eclipse"`
 
+	// Labels of the named areas crossed
+	Areas []string `json:"areas,omitempty" xml:"area,omitempty"`
+
+	// Percentage of the solar disk occulted by the earth
+	Occulted float64 `json:"occulted" xml:"occulted"`
+
 	converted bool
 }
 
@@ -50,19 +63,14 @@ func (p Point) CNES() Point {
 	n := p
 	n.converted = true
 	n.Lat, n.Lon, n.Alt = p.toECEF()
+	n.Vx, n.Vy, n.Vz = p.toECEFVelocity()
 	return n
 }
 
+// Dublin gives the same coordinates as CNES. Both used to differ by the
+// origin of the julian date of their sidereal time.
 func (p Point) Dublin() Point {
-	if p.converted {
-		return p
-	}
-	n := p
-	n.converted = true
-	vs := []float64{p.Lat, p.Lon, p.Alt}
-	cs := ecefCoordinates(gstTime(p.When), vs)
-	n.Lat, n.Lon, n.Alt = cs[0], cs[1], cs[2]
-	return n
+	return p.CNES()
 }
 
 func (p Point) Classic() Point {
@@ -72,6 +80,7 @@ func (p Point) Classic() Point {
 	n := p
 	n.Lat, n.Lon, n.Alt = ConvertTEME(p.When, []float64{n.Lat, n.Lon, n.Alt})
 	n.Alt = n.Alt / 1000
+	n.Vx, n.Vy, n.Vz = p.toECEFVelocity()
 	return n
 }
 
@@ -82,6 +91,7 @@ func (p Point) Geocentric() Point {
 	n := p
 	n.converted = true
 	n.Lat, n.Lon, n.Alt = coord.GeocentricFromECEF(p.toECEF())
+	n.Vx, n.Vy, n.Vz = p.toECEFVelocity()
 	return n
 }
 
@@ -92,21 +102,61 @@ func (p Point) Geodetic() Point {
 	n := p
 	n.converted = true
 	n.Lat, n.Lon, n.Alt = coord.GeodeticFromECEF(p.toECEF())
+	n.Vx, n.Vy, n.Vz = p.toECEFVelocity()
+	return n
+}
+
+// J2000 gives the position and velocity of the satellite in the mean equator
+// and equinox of J2000 (EME2000).
+func (p Point) J2000() Point {
+	if p.converted {
+		return p
+	}
+	n := p
+	n.converted = true
+	tt := timescale.TT.FromUTC(p.When)
+	n.Lat, n.Lon, n.Alt = coord.Teme2J2000(tt, p.Lat, p.Lon, p.Alt)
+	n.Vx, n.Vy, n.Vz = coord.Teme2J2000(tt, p.Vx, p.Vy, p.Vz)
+	return n
+}
+
+// GCRF gives the position and velocity of the satellite in the GCRF (J2000
+// corrected by the celestial pole offsets of the earth orientation parameters).
+func (p Point) GCRF() Point {
+	if p.converted {
+		return p
+	}
+	n := p
+	n.converted = true
+	tt, eop := timescale.TT.FromUTC(p.When), orientation(p.When)
+	n.Lat, n.Lon, n.Alt = coord.Teme2GCRF(tt, eop, p.Lat, p.Lon, p.Alt)
+	n.Vx, n.Vy, n.Vz = coord.Teme2GCRF(tt, eop, p.Vx, p.Vy, p.Vz)
 	return n
 }
 
+// toECEF gives the position of the satellite in the earth fixed frame.
 func (p Point) toECEF() (float64, float64, float64) {
-	vs := []float64{p.Lat, p.Lon, p.Alt}
-	cs := ecefCoordinates(gstTimeBis(p.Epoch), vs)
-	return cs[0], cs[1], cs[2]
+	return coord.Teme2ITRF(p.When, orientation(p.When), p.Lat, p.Lon, p.Alt)
+}
+
+// toECEFVelocity gives the velocity of the satellite in the earth fixed frame.
+func (p Point) toECEFVelocity() (float64, float64, float64) {
+	return coord.TemeVelocity2ITRF(p.When, orientation(p.When), p.Lat, p.Lon, p.Alt, p.Vx, p.Vy, p.Vz)
 }
 
 type Shape interface {
 	Contains(p Point) bool
 }
 
+// Area is a shape identified by a label.
+type Area struct {
+	Label string
+	Shape
+}
+
 type Element struct {
 	Sid  int
+	Name string
 	When time.Time
 	JD   float64
 	JDF  float64
@@ -114,12 +164,15 @@ type Element struct {
 	Base time.Time
 
 	//Elements of row#1
-	Year      int
-	Doy       float64
-	Mean1     float64
-	Mean2     float64
-	BStar     float64
-	Ephemeris int
+	Class      string
+	Designator string
+	Year       int
+	Doy        float64
+	Mean1      float64
+	Mean2      float64
+	BStar      float64
+	Ephemeris  int
+	Set        int
 
 	//Elements of row#2
 	Inclination  float64
@@ -133,21 +186,47 @@ type Element struct {
 	TLE []string
 }
 
+// NewElement parses the rows of a TLE in strict mode.
 func NewElement(row1, row2 string) (*Element, error) {
-	var e Element
+	return ParseElement(row1, row2, Strict)
+}
+
+// ParseElement parses the rows of a TLE. When the rows can be parsed far enough
+// to know the satellite number, the (incomplete) element is returned with the
+// error.
+func ParseElement(row1, row2 string, mode ParseMode) (*Element, error) {
+	if mode == Lenient {
+		row1, row2 = padRow(row1), padRow(row2)
+	}
 	if len(row1) != tleLen {
 		return nil, InvalidLenError(len(row1))
 	}
 	if len(row2) != tleLen {
 		return nil, InvalidLenError(len(row2))
 	}
+	var e Element
 	if err := scanLine1(row1, &e); err != nil {
-		return nil, err
+		if e.Sid == 0 {
+			return nil, err
+		}
+		return &e, err
 	}
-	if err := scanLine2(row2, &e); err != nil {
-		return nil, err
+	sid, err := scanLine2(row2, &e)
+	if err != nil {
+		return &e, err
 	}
 	e.TLE = []string{row1, row2}
+	if mode == Lenient {
+		return &e, nil
+	}
+	if sid != e.Sid {
+		return &e, &ParseError{row: 2, cause: fmt.Errorf("satellite number %d does not match row #1 (%d)", sid, e.Sid)}
+	}
+	for i, r := range e.TLE {
+		if err := verifyChecksum(r, i+1); err != nil {
+			return &e, err
+		}
+	}
 	return &e, nil
 }
 
@@ -161,9 +240,70 @@ func (e Element) Range(i time.Duration) (time.Time, time.Time) {
 	return t, t.Add(i)
 }
 
-func (e Element) Predict(p, s time.Duration, saa Shape) (*Result, error) {
+func (e Element) Predict(p, s time.Duration, saa Shape, areas ...Area) (*Result, error) {
+	g, err := e.propagator()
+	if err != nil {
+		return &Result{Sid: e.Sid, Name: e.Name, TLE: e.TLE, Epoch: g.epoch, Err: err}, err
+	}
+	defer g.Close()
+
+	var (
+		ts []*Point
+		js []float64
+		es [][]float64
+	)
+	delta := s.Seconds() / time.Minute.Seconds()
+	var when float64
+	if !e.Base.IsZero() {
+		delta := e.Base.Sub(e.When)
+		when = delta.Seconds() / time.Minute.Seconds()
+	}
+	for elapsed := time.Duration(0); elapsed < p; elapsed += s {
+		t, err := g.At(when)
+		if err != nil {
+			return &Result{Sid: e.Sid, Name: e.Name, TLE: e.TLE, Epoch: g.epoch, Points: ts, Err: err}, err
+		}
+		// TODO: compute eclipse on/off when knowing position of satellite
+		es = append(es, []float64{t.Lat * 1000, t.Lon * 1000, t.Alt * 1000})
+		if saa != nil {
+			t.Saa = saa.Contains(*t)
+		}
+		for _, a := range areas {
+			if a.Contains(*t) {
+				t.Areas = append(t.Areas, a.Label)
+			}
+		}
+		ts = append(ts, t)
+		js = append(js, t.Epoch)
+
+		when += delta
+	}
+	fes, pes, ocs := eclipseStatus(es, js)
+	for i := 0; i < len(ts); i++ {
+		ts[i].Total = fes[i]
+		ts[i].Partial = pes[i]
+		ts[i].Occulted = ocs[i] * 100
+	}
+	return &Result{Sid: e.Sid, Name: e.Name, TLE: e.TLE, Epoch: g.epoch, Points: ts}, nil
+}
+
+// At gives the position and velocity of the satellite in the TEME frame at w.
+func (e Element) At(w time.Time) (*Point, error) {
+	g, err := e.propagator()
+	if err != nil {
+		return nil, err
+	}
+	defer g.Close()
+	return g.At(g.Since(w))
+}
+
+type propagator struct {
+	els   sgp.Elsetrec
+	epoch float64
+}
+
+func (e Element) propagator() (*propagator, error) {
 	els := sgp.NewElsetrec()
-	defer sgp.DeleteElsetrec(els)
 
 	els.SetNumber(int64(e.Sid))
 	els.SetYear(e.Year)
@@ -182,148 +322,137 @@ func (e Element) Predict(p, s time.Duration, saa Shape) (*Result, error) {
 	els.SetAnomaly(e.Anomaly)
 	els.SetMotion(e.Motion)
 	els.SetAscension(e.Ascension)
-	epoch := els.GetJdsatepoch() + els.GetJdsatepochF()
+	g := propagator{
+		els:   els,
+		epoch: els.GetJdsatepoch() + els.GetJdsatepochF(),
+	}
 	wg84 := sgp.Gravconsttype(sgp.Wgs84)
 	// TODO: move sgp4init in sgp package with func Init(e Elsetrec)
-	if ok := sgp.Sgp4init(wg84, 'i', int(els.GetNumber()), epoch, els.GetBstar(), els.GetMean1(), els.GetMean2(), els.GetExcentricity(), els.GetPerigee(), els.GetInclination(), els.GetAnomaly(), els.GetMotion(), els.GetAscension(), els); !ok {
+	if ok := sgp.Sgp4init(wg84, 'i', int(els.GetNumber()), g.epoch, els.GetBstar(), els.GetMean1(), els.GetMean2(), els.GetExcentricity(), els.GetPerigee(), els.GetInclination(), els.GetAnomaly(), els.GetMotion(), els.GetAscension(), els); !ok {
 		// return nil, fmt.Errorf("fail to initialize projection: %d", els.GetError())
 		err := PropagationError(els.GetError())
-		return &Result{TLE: e.TLE, Epoch: epoch, Err: err}, err
+		sgp.DeleteElsetrec(els)
+		return &g, err
 	}
+	return &g, nil
+}
 
-	var (
-		ts []*Point
-		js []float64
-		es [][]float64
-	)
-	delta := s.Seconds() / time.Minute.Seconds()
-	var when float64
-	if !e.Base.IsZero() {
-		delta := e.Base.Sub(e.When)
-		when = delta.Seconds() / time.Minute.Seconds()
+func (g *propagator) Close() {
+	sgp.DeleteElsetrec(g.els)
+}
+
+// At gives the position and velocity of the satellite in the TEME frame at
+// when minutes since the epoch of the element.
+func (g *propagator) At(when float64) (*Point, error) {
+	ps, vs, err := sgp.SGP4(g.els, when)
+	if err != nil {
+		return nil, PropagationError(g.els.GetError())
 	}
-	for elapsed := time.Duration(0); elapsed < p; elapsed += s {
-		ps, _, err := sgp.SGP4(els, when)
-		if err != nil {
-			err := PropagationError(els.GetError())
-			return &Result{TLE: e.TLE, Epoch: epoch, Points: ts, Err: err}, err
-		}
-		// TODO: wrap Invjday in sgp package with func Date(jd, jdf) time.Time
-		var (
-			year, month, day, hour, min int
-			seconds                     float64
-		)
-		jd := els.GetJdsatepoch()
-		jdf := els.GetJdsatepochF() + (when / minPerDays)
-		if jdf < 0 {
-			jd -= 1.0
-			jdf += 1.0
-		}
+	jd, jdf := g.julian(when)
+	t := Point{
+		Lat:   ps[0],
+		Lon:   ps[1],
+		Alt:   ps[2],
+		Vx:    vs[0],
+		Vy:    vs[1],
+		Vz:    vs[2],
+		When:  g.Time(when),
+		Epoch: jd + jdf,
+	}
+	t.Speed = norm(vs)
+	x, y, z := t.toECEF()
+	vx, vy, vz := t.toECEFVelocity()
+	t.Ground = groundSpeed([]float64{x, y, z}, []float64{vx, vy, vz})
 
-		sgp.Invjday(jd, jdf, &year, &month, &day, &hour, &min, &seconds)
-		cs, ns := math.Modf(seconds)
-		w := time.Date(year, time.Month(month), day, hour, min, int(cs), int(ns*1e9), time.UTC)
+	return &t, nil
+}
 
-		t := Point{
-			Lat:   ps[0],
-			Lon:   ps[1],
-			Alt:   ps[2],
-			When:  w,
-			Epoch: jd + jdf,
-		}
+// Time gives the UTC time at when minutes since the epoch of the element.
+func (g *propagator) Time(when float64) time.Time {
+	// TODO: wrap Invjday in sgp package with func Date(jd, jdf) time.Time
+	var (
+		year, month, day, hour, min int
+		seconds                     float64
+	)
+	jd, jdf := g.julian(when)
+	sgp.Invjday(jd, jdf, &year, &month, &day, &hour, &min, &seconds)
+	cs, ns := math.Modf(seconds)
+	return time.Date(year, time.Month(month), day, hour, min, int(cs), int(ns*1e9), time.UTC)
+}
 
-		for i := range ps {
-			ps[i] *= 1000
-		}
-		// TODO: compute eclipse on/off when knowing position of satellite
-		es = append(es, ps)
-		if saa != nil {
-			t.Saa = saa.Contains(t)
-		}
-		ts = append(ts, &t)
-		js = append(js, t.Epoch)
+// Since gives the number of minutes elapsed between the epoch of the element
+// and t.
+func (g *propagator) Since(t time.Time) float64 {
+	return t.Sub(g.Time(0)).Minutes()
+}
 
-		when += delta
-	}
-	fes, pes := eclipseStatus(es, js)
-	for i := 0; i < len(ts); i++ {
-		ts[i].Total = fes[i]
-		ts[i].Partial = pes[i]
+func (g *propagator) julian(when float64) (float64, float64) {
+	jd := g.els.GetJdsatepoch()
+	jdf := g.els.GetJdsatepochF() + (when / minPerDays)
+	if jdf < 0 {
+		jd -= 1.0
+		jdf += 1.0
 	}
-	return &Result{TLE: e.TLE, Epoch: epoch, Points: ts}, nil
+	return jd, jdf
 }
 
 func scanLine1(r string, e *Element) error {
-	r1 := struct {
-		Line      int
-		Satellite int
-		Class     string
-		Label     string
-		Year      int
-		Doy       float64
-		Mean1     float64
-		Mean2     float64
-		Mean2Exp  int
-		BStar     float64
-		BStarExp  int
-		Ephemeris int
-		Control   string
-	}{}
-	if _, err := fmt.Sscanf(r, row1, &r1.Line, &r1.Satellite, &r1.Class, &r1.Label, &r1.Year, &r1.Doy, &r1.Mean1, &r1.Mean2, &r1.Mean2Exp, &r1.BStar, &r1.BStarExp, &r1.Ephemeris, &r1.Control); err != nil || r1.Line != 1 {
-		return &ParseError{row: 1, cause: err}
+	c := columns{row: r, num: 1}
+	if c.int(1, 1) != 1 {
+		return &ParseError{row: 1, cause: fmt.Errorf("invalid line number %s", c.text(1, 1))}
+	}
+	e.Sid = c.catalog(3, 7)
+	e.Class = c.text(8, 8)
+	e.Designator = c.text(10, 17)
+	year := c.int(19, 20)
+	e.Year = year
+	e.Doy = c.float(21, 32)
+	e.Mean1 = c.float(34, 43) / (xpdotp * minPerDays)
+	e.Mean2 = c.exponent(45, 52) / (xpdotp * minPerDays * minPerDays)
+	e.BStar = c.exponent(54, 61)
+	e.Ephemeris = c.int(63, 63)
+	e.Set = c.int(65, 68)
+	if c.err != nil {
+		return c.err
 	}
-	mean1 := r1.Mean1 / (xpdotp * minPerDays)
-	mean2 := ((r1.Mean2 / 100000) * math.Pow10(r1.Mean2Exp)) / (xpdotp * minPerDays * minPerDays)
-	bstar := (r1.BStar / 100000) * math.Pow10(r1.BStarExp)
-
-	e.Sid = r1.Satellite
-	e.Year = r1.Year
-	e.Doy = r1.Doy
-	e.Mean1 = mean1
-	e.Mean2 = mean2
-	e.BStar = bstar
-	e.Ephemeris = r1.Ephemeris
 
-	var (
-		month, day, hour, min int
-		seconds               float64
-	)
-	if r1.Year < YPivot {
-		r1.Year += Y2000
+	if year < YPivot {
+		year += Y2000
 	} else {
-		r1.Year += Y1900
+		year += Y1900
 	}
-	sgp.Days2mdhms(r1.Year, r1.Doy, &month, &day, &hour, &min, &seconds)
-	sgp.Jday(r1.Year, month, day, hour, min, seconds, &e.JD, &e.JDF)
-
-	e.When = time.Date(r1.Year, time.Month(month), day, hour, min, int(seconds), 0, time.UTC)
+	e.setEpoch(year, e.Doy)
 
 	return nil
 }
 
-func scanLine2(r string, e *Element) error {
-	r2 := struct {
-		Line         int
-		Satellite    int
-		Inclination  float64
-		Ascension    float64
-		Excentricity float64
-		Perigee      float64
-		Anomaly      float64
-		Motion       float64
-		Revolution   int
-		Control      string
-	}{}
-
-	if _, err := fmt.Sscanf(r, row2, &r2.Line, &r2.Satellite, &r2.Inclination, &r2.Ascension, &r2.Excentricity, &r2.Perigee, &r2.Anomaly, &r2.Motion, &r2.Revolution, &r2.Control); err != nil || r2.Line != 2 {
-		return &ParseError{row: 2, cause: err}
-	}
-	e.Inclination = r2.Inclination * deg2rad
-	e.Ascension = r2.Ascension * deg2rad
-	e.Excentricity = r2.Excentricity / 10000000
-	e.Perigee = r2.Perigee * deg2rad
-	e.Anomaly = r2.Anomaly * deg2rad
-	e.Motion = r2.Motion / xpdotp
+// setEpoch sets the julian date and the time of the epoch of the element from
+// the (four digits) year and the fractional day of the year.
+func (e *Element) setEpoch(year int, doy float64) {
+	var (
+		month, day, hour, min int
+		seconds, jd, jdf      float64
+	)
+	sgp.Days2mdhms(year, doy, &month, &day, &hour, &min, &seconds)
+	sgp.Jday(year, month, day, hour, min, seconds, &jd, &jdf)
+	e.JD, e.JDF = jd, jdf
 
-	return nil
+	e.When = time.Date(year, time.Month(month), day, hour, min, int(seconds), 0, time.UTC)
+}
+
+func scanLine2(r string, e *Element) (int, error) {
+	c := columns{row: r, num: 2}
+	if c.int(1, 1) != 2 {
+		return 0, &ParseError{row: 2, cause: fmt.Errorf("invalid line number %s", c.text(1, 1))}
+	}
+	sid := c.catalog(3, 7)
+	e.Inclination = c.float(9, 16) * deg2rad
+	e.Ascension = c.float(18, 25) * deg2rad
+	e.Excentricity = c.decimal(27, 33)
+	e.Perigee = c.float(35, 42) * deg2rad
+	e.Anomaly = c.float(44, 51) * deg2rad
+	e.Motion = c.float(53, 63) / xpdotp
+	e.Revolution = c.int(64, 68)
+
+	return sid, c.err
 }
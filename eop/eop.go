@@ -0,0 +1,330 @@
+// Package eop provides the earth orientation parameters (polar motion, UT1-UTC,
+// length of day and celestial pole offsets) published by the IERS and the
+// number of leap seconds (TAI-UTC).
+//
+// The parameters are read from the IERS finals2000A files or from the EOP
+// files in CSV published by CelesTrak. Without a file, the snapshot of the EOP
+// file of CelesTrak embedded in the package is used. Outside of its dates, the
+// polar motion is ignored, UT1 is UTC and TAI-UTC is given by the table of leap
+// seconds embedded in the package.
+package eop
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/busoc/inspect/coord"
+)
+
+// Record gives the earth orientation parameters of one day (at 0h UTC).
+type Record struct {
+	MJD float64
+
+	// Coordinates of the pole (arcseconds)
+	X float64
+	Y float64
+
+	// UT1-UTC and excess of the length of day (seconds)
+	DUT1 float64
+	LOD  float64
+
+	// Celestial pole offsets of the IAU-80 nutation (arcseconds), only given
+	// by the CSV files
+	DPsi float64
+	DEps float64
+
+	// TAI-UTC (seconds), 0 when not given by the file
+	DAT float64
+}
+
+// When gives the time of the record.
+func (r Record) When() time.Time {
+	return coord.FromJulianDate(r.MJD + coord.DeltaMJD)
+}
+
+// Table is a set of records sorted by date. A nil Table gives no correction.
+type Table struct {
+	records []Record
+
+	// bounded gives no correction outside of the records instead of using the
+	// first or last one
+	bounded bool
+}
+
+// snapshot is the EOP file of CelesTrak embedded in the package. Refresh it
+// with go generate.
+//
+//go:generate curl -sSf -o EOP-All.csv https://celestrak.org/SpaceData/EOP-All.csv
+//go:embed EOP-All.csv
+var snapshot []byte
+
+var (
+	mu       sync.RWMutex
+	current  *Table
+	embedded *Table
+)
+
+func init() {
+	t, err := Parse(bytes.NewReader(snapshot))
+	if err != nil {
+		return
+	}
+	t.bounded = true
+	embedded = t
+}
+
+// Use sets the table used by the transformations of the earth fixed frame and
+// by the conversions of the time scales. A nil table restores the embedded
+// snapshot.
+func Use(t *Table) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = t
+}
+
+// Current gives the table set by Use or the embedded snapshot when none was
+// set.
+func Current() *Table {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current != nil {
+		return current
+	}
+	return embedded
+}
+
+// Embedded reports whether t is the embedded snapshot.
+func (t *Table) Embedded() bool {
+	return t != nil && t == embedded
+}
+
+// Load reads the records of file. The format (finals2000A or CSV) is detected
+// from its content.
+func Load(file string) (*Table, error) {
+	r, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	t, err := Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return t, nil
+}
+
+// Parse reads the records from r in the finals2000A or CSV format.
+func Parse(r io.Reader) (*Table, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rs []Record
+	if bytes.HasPrefix(bytes.TrimSpace(buf), []byte("DATE")) {
+		rs, err = parseCSV(buf)
+	} else {
+		rs, err = parseFinals(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) == 0 {
+		return nil, fmt.Errorf("no earth orientation parameters found")
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].MJD < rs[j].MJD })
+	return &Table{records: rs}, nil
+}
+
+// Range gives the first and last date of the table.
+func (t *Table) Range() (time.Time, time.Time) {
+	if t == nil || len(t.records) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return t.records[0].When(), t.records[len(t.records)-1].When()
+}
+
+// At gives the earth orientation parameters at w interpolated between the
+// records of the surrounding days. The first or last record is used outside of
+// the table, except for the embedded snapshot that gives no correction.
+func (t *Table) At(w time.Time) coord.EOP {
+	if t == nil || len(t.records) == 0 {
+		return coord.EOP{}
+	}
+	mjd := coord.JulianDate(w) - coord.DeltaMJD
+	if t.bounded && (mjd < t.records[0].MJD || mjd > t.records[len(t.records)-1].MJD) {
+		return coord.EOP{}
+	}
+	i := sort.Search(len(t.records), func(i int) bool { return t.records[i].MJD > mjd })
+	switch {
+	case i == 0:
+		return t.records[0].eop()
+	case i == len(t.records):
+		return t.records[i-1].eop()
+	}
+	prev, next := t.records[i-1], t.records[i]
+	frac := (mjd - prev.MJD) / (next.MJD - prev.MJD)
+
+	// UT1-UTC jumps by one second with a leap second
+	dut1 := next.DUT1
+	if delta := next.DUT1 - prev.DUT1; math.Abs(delta) > 0.5 {
+		dut1 -= math.Copysign(1, delta)
+	}
+	return coord.EOP{
+		X:    prev.X + frac*(next.X-prev.X),
+		Y:    prev.Y + frac*(next.Y-prev.Y),
+		DUT1: prev.DUT1 + frac*(dut1-prev.DUT1),
+		LOD:  prev.LOD + frac*(next.LOD-prev.LOD),
+		DPsi: prev.DPsi + frac*(next.DPsi-prev.DPsi),
+		DEps: prev.DEps + frac*(next.DEps-prev.DEps),
+	}
+}
+
+// Offsets reports whether the table gives the celestial pole offsets DPsi and
+// DEps. The finals2000A files only give the offsets of the IAU-2000 model and
+// are read without them.
+func (t *Table) Offsets() bool {
+	if t == nil {
+		return false
+	}
+	for _, r := range t.records {
+		if r.DPsi != 0 || r.DEps != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DUT1 gives UT1-UTC (seconds) at w.
+func (t *Table) DUT1(w time.Time) float64 {
+	return t.At(w).DUT1
+}
+
+// PolarMotion gives the coordinates of the pole (arcseconds) at w.
+func (t *Table) PolarMotion(w time.Time) (float64, float64) {
+	e := t.At(w)
+	return e.X, e.Y
+}
+
+// TAI gives TAI-UTC (seconds) at w from the record of the day when given by
+// the file or from the embedded table of leap seconds.
+func (t *Table) TAI(w time.Time) float64 {
+	if t != nil && len(t.records) > 0 {
+		mjd := math.Floor(coord.JulianDate(w) - coord.DeltaMJD)
+		i := sort.Search(len(t.records), func(i int) bool { return t.records[i].MJD >= mjd })
+		if i < len(t.records) && t.records[i].MJD == mjd && t.records[i].DAT > 0 {
+			return t.records[i].DAT
+		}
+	}
+	return LeapSeconds(w)
+}
+
+func (r Record) eop() coord.EOP {
+	return coord.EOP{X: r.X, Y: r.Y, DUT1: r.DUT1, LOD: r.LOD, DPsi: r.DPsi, DEps: r.DEps}
+}
+
+// parseFinals reads the Bulletin A values of the rows of a finals2000A file.
+// The rows without UT1-UTC (end of the predictions) are skipped.
+func parseFinals(buf []byte) ([]Record, error) {
+	var rs []Record
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for n := 1; s.Scan(); n++ {
+		row := s.Text()
+		if strings.TrimSpace(row) == "" {
+			continue
+		}
+		if len(row) < 68 || strings.TrimSpace(row[58:68]) == "" {
+			continue
+		}
+		var (
+			r   Record
+			err error
+		)
+		for _, f := range []struct {
+			v        *float64
+			from, to int
+		}{
+			{&r.MJD, 7, 15},
+			{&r.X, 18, 27},
+			{&r.Y, 37, 46},
+			{&r.DUT1, 58, 68},
+		} {
+			if *f.v, err = strconv.ParseFloat(strings.TrimSpace(row[f.from:f.to]), 64); err != nil {
+				return nil, fmt.Errorf("row %d: %s", n, err)
+			}
+		}
+		if len(row) >= 86 {
+			if lod, err := strconv.ParseFloat(strings.TrimSpace(row[79:86]), 64); err == nil {
+				r.LOD = lod / 1000
+			}
+		}
+		rs = append(rs, r)
+	}
+	return rs, s.Err()
+}
+
+// parseCSV reads the rows of an EOP file of CelesTrak. The columns are found
+// from the header.
+func parseCSV(buf []byte) ([]Record, error) {
+	rs := csv.NewReader(bytes.NewReader(buf))
+	rs.FieldsPerRecord = -1
+	head, err := rs.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i, h := range head {
+		index[strings.ToUpper(strings.TrimSpace(h))] = i
+	}
+	for _, k := range []string{"MJD", "X", "Y", "UT1-UTC"} {
+		if _, ok := index[k]; !ok {
+			return nil, fmt.Errorf("missing column %s", k)
+		}
+	}
+	var es []Record
+	for n := 2; ; n++ {
+		row, err := rs.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var r Record
+		for _, f := range []struct {
+			v   *float64
+			key string
+		}{
+			{&r.MJD, "MJD"},
+			{&r.X, "X"},
+			{&r.Y, "Y"},
+			{&r.DUT1, "UT1-UTC"},
+			{&r.LOD, "LOD"},
+			{&r.DPsi, "DPSI"},
+			{&r.DEps, "DEPS"},
+			{&r.DAT, "DAT"},
+		} {
+			i, ok := index[f.key]
+			if !ok || i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				continue
+			}
+			if *f.v, err = strconv.ParseFloat(strings.TrimSpace(row[i]), 64); err != nil {
+				return nil, fmt.Errorf("row %d: %s", n, err)
+			}
+		}
+		es = append(es, r)
+	}
+	return es, nil
+}
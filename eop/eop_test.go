@@ -0,0 +1,54 @@
+package eop
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func ExampleParse() {
+	const rows = `DATE,MJD,X,Y,UT1-UTC,LOD,DPSI,DEPS,DX,DY,DAT,DATA_TYPE
+2016-12-31,57753,0.076606,0.264936,0.5925590,0.0012810,-0.104005,-0.009022,0.000034,-0.000126,36,O
+2017-01-01,57754,0.074855,0.265735,-0.4083740,0.0010180,-0.104067,-0.009082,0.000079,-0.000129,37,O
+`
+	t, err := Parse(strings.NewReader(rows))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	w := time.Date(2016, 12, 31, 18, 0, 0, 0, time.UTC)
+	e := t.At(w)
+	fmt.Printf("x: %.6f, y: %.6f, ut1-utc: %.7f, tai-utc: %.0f", e.X, e.Y, e.DUT1, t.TAI(w))
+	// Output:
+	// x: 0.075293, y: 0.265535, ut1-utc: 0.5918592, tai-utc: 36
+}
+
+func ExampleLeapSeconds() {
+	for _, w := range []time.Time{
+		time.Date(2004, 4, 6, 7, 51, 28, 0, time.UTC),
+		time.Date(2018, 10, 31, 8, 37, 20, 0, time.UTC),
+	} {
+		fmt.Printf("%s: %.0f", w.Format("2006-01-02"), LeapSeconds(w))
+		fmt.Println()
+	}
+	// Output:
+	// 2004-04-06: 32
+	// 2018-10-31: 37
+}
+
+func ExampleCurrent() {
+	t := Current()
+	for _, w := range []time.Time{
+		time.Date(2016, 12, 31, 18, 0, 0, 0, time.UTC),
+		time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		e := t.At(w)
+		fmt.Printf("%s: x: %.6f, y: %.6f, ut1-utc: %.7f", w.Format("2006-01-02"), e.X, e.Y, e.DUT1)
+		fmt.Println()
+	}
+	fmt.Println(t.Embedded())
+	// Output:
+	// 2016-12-31: x: 0.075293, y: 0.265535, ut1-utc: 0.5918592
+	// 1950-01-01: x: 0.000000, y: 0.000000, ut1-utc: 0.0000000
+	// true
+}
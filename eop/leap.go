@@ -0,0 +1,59 @@
+package eop
+
+import (
+	"time"
+)
+
+type leap struct {
+	When time.Time
+	DAT  float64
+}
+
+// leaps is the table of the leap seconds introduced since 1972.
+var leaps = []leap{
+	{When: date(1972, 1), DAT: 10},
+	{When: date(1972, 7), DAT: 11},
+	{When: date(1973, 1), DAT: 12},
+	{When: date(1974, 1), DAT: 13},
+	{When: date(1975, 1), DAT: 14},
+	{When: date(1976, 1), DAT: 15},
+	{When: date(1977, 1), DAT: 16},
+	{When: date(1978, 1), DAT: 17},
+	{When: date(1979, 1), DAT: 18},
+	{When: date(1980, 1), DAT: 19},
+	{When: date(1981, 7), DAT: 20},
+	{When: date(1982, 7), DAT: 21},
+	{When: date(1983, 7), DAT: 22},
+	{When: date(1985, 7), DAT: 23},
+	{When: date(1988, 1), DAT: 24},
+	{When: date(1990, 1), DAT: 25},
+	{When: date(1991, 1), DAT: 26},
+	{When: date(1992, 7), DAT: 27},
+	{When: date(1993, 7), DAT: 28},
+	{When: date(1994, 7), DAT: 29},
+	{When: date(1996, 1), DAT: 30},
+	{When: date(1997, 7), DAT: 31},
+	{When: date(1999, 1), DAT: 32},
+	{When: date(2006, 1), DAT: 33},
+	{When: date(2009, 1), DAT: 34},
+	{When: date(2012, 7), DAT: 35},
+	{When: date(2015, 7), DAT: 36},
+	{When: date(2017, 1), DAT: 37},
+}
+
+// LeapSeconds gives TAI-UTC (seconds) at w from the embedded table of leap
+// seconds. The offset of 1972 is used before 1972.
+func LeapSeconds(w time.Time) float64 {
+	dat := leaps[0].DAT
+	for _, l := range leaps {
+		if w.Before(l.When) {
+			break
+		}
+		dat = l.DAT
+	}
+	return dat
+}
+
+func date(year int, month time.Month) time.Time {
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
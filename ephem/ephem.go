@@ -0,0 +1,82 @@
+// Package ephem gives the positions of the sun and of the moon seen from the
+// centre of the earth.
+//
+// The sun is computed from the truncated VSOP87 theory of the earth and the
+// moon from the truncated ELP-2000/82 theory as given by J. Meeus (Astronomical
+// Algorithms, chapters 25 and 47). Both positions are apparent: they include
+// the IAU-80 nutation and, for the sun, the aberration (light-time). They are
+// given in the ecliptic of date, in the TEME frame and in the earth fixed frame.
+package ephem
+
+import (
+	"math"
+	"time"
+
+	"github.com/busoc/inspect/coord"
+	"github.com/busoc/inspect/timescale"
+)
+
+const (
+	deg2rad = math.Pi / 180
+	rad2deg = 180 / math.Pi
+
+	// astronomical unit (kilometers)
+	au = 149597870.7
+)
+
+// Sun gives the position (kilometers) of the sun in the TEME frame at w (UTC).
+func Sun(w time.Time) (float64, float64, float64) {
+	tt := timescale.TT.FromUTC(w)
+	lon, lat, dist := SunEcliptic(tt)
+	return ecliptic2Teme(tt, lon, lat, dist)
+}
+
+// SunECEF gives the position (kilometers) of the sun in the earth fixed frame
+// at w (UTC) with the earth orientation parameters eop.
+func SunECEF(w time.Time, eop coord.EOP) (float64, float64, float64) {
+	x, y, z := Sun(w)
+	return coord.Teme2ITRF(w, eop, x, y, z)
+}
+
+// Moon gives the position (kilometers) of the moon in the TEME frame at w
+// (UTC).
+func Moon(w time.Time) (float64, float64, float64) {
+	tt := timescale.TT.FromUTC(w)
+	lon, lat, dist := MoonEcliptic(tt)
+	return ecliptic2Teme(tt, lon, lat, dist)
+}
+
+// MoonECEF gives the position (kilometers) of the moon in the earth fixed frame
+// at w (UTC) with the earth orientation parameters eop.
+func MoonECEF(w time.Time, eop coord.EOP) (float64, float64, float64) {
+	x, y, z := Moon(w)
+	return coord.Teme2ITRF(w, eop, x, y, z)
+}
+
+// ecliptic2Teme transforms the apparent longitude and latitude (degrees) and
+// the distance given in the ecliptic of date to the TEME frame: the true
+// obliquity gives the true equator and equinox of date, then the equation of
+// the equinoxes the mean equinox of the TEME frame.
+func ecliptic2Teme(tt time.Time, lon, lat, dist float64) (float64, float64, float64) {
+	psi, deps, eps := coord.Nutation(tt)
+
+	lon, lat = lon*deg2rad, lat*deg2rad
+	var (
+		obl = eps + deps
+		x   = dist * math.Cos(lat) * math.Cos(lon)
+		y   = dist * (math.Cos(lat)*math.Sin(lon)*math.Cos(obl) - math.Sin(lat)*math.Sin(obl))
+		z   = dist * (math.Cos(lat)*math.Sin(lon)*math.Sin(obl) + math.Sin(lat)*math.Cos(obl))
+	)
+	eqe := psi * math.Cos(eps)
+	cos, sin := math.Cos(eqe), math.Sin(eqe)
+	return cos*x + sin*y, -sin*x + cos*y, z
+}
+
+// normalize puts the angle a (degrees) in the range [0:360[.
+func normalize(a float64) float64 {
+	a = math.Mod(a, 360)
+	if a < 0 {
+		a += 360
+	}
+	return a
+}
@@ -0,0 +1,22 @@
+package ephem
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleSunEcliptic() {
+	tt := time.Date(1992, 10, 13, 0, 0, 0, 0, time.UTC)
+	lon, lat, dist := SunEcliptic(tt)
+	fmt.Printf("lon: %.6f, lat: %.6f, dist: %.8f", lon, lat, dist/au)
+	// Output:
+	// lon: 199.906060, lat: 0.000173, dist: 0.99760775
+}
+
+func ExampleMoonEcliptic() {
+	tt := time.Date(1992, 4, 12, 0, 0, 0, 0, time.UTC)
+	lon, lat, dist := MoonEcliptic(tt)
+	fmt.Printf("lon: %.6f, lat: %.6f, dist: %.1f", lon, lat, dist)
+	// Output:
+	// lon: 133.167265, lat: -3.229126, dist: 368409.7
+}
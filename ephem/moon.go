@@ -0,0 +1,198 @@
+package ephem
+
+import (
+	"math"
+	"time"
+
+	"github.com/busoc/inspect/coord"
+)
+
+// MoonEcliptic gives the apparent longitude and latitude (degrees) and the
+// distance (kilometers) of the moon in the ecliptic of date at tt (terrestrial
+// time).
+func MoonEcliptic(tt time.Time) (float64, float64, float64) {
+	t := coord.Centuries(tt)
+	var (
+		lp = normalize(218.3164477 + 481267.88123421*t - 0.0015786*t*t + t*t*t/538841 - t*t*t*t/65194000)
+		d  = normalize(297.8501921 + 445267.1114034*t - 0.0018819*t*t + t*t*t/545868 - t*t*t*t/113065000)
+		m  = normalize(357.5291092 + 35999.0502909*t - 0.0001536*t*t + t*t*t/24490000)
+		mp = normalize(134.9633964 + 477198.8675055*t + 0.0087414*t*t + t*t*t/69699 - t*t*t*t/14712000)
+		f  = normalize(93.272095 + 483202.0175233*t - 0.0036539*t*t - t*t*t/3526000 + t*t*t*t/863310000)
+
+		a1 = normalize(119.75+131.849*t) * deg2rad
+		a2 = normalize(53.09+479264.29*t) * deg2rad
+		a3 = normalize(313.45+481266.484*t) * deg2rad
+
+		// decrease of the eccentricity of the orbit of the earth
+		e = 1 - 0.002516*t - 0.0000074*t*t
+	)
+	eccentricity := func(m float64) float64 {
+		switch math.Abs(m) {
+		case 1:
+			return e
+		case 2:
+			return e * e
+		default:
+			return 1
+		}
+	}
+
+	var sl, sr, sb float64
+	for _, x := range moonLR {
+		arg := (x.D*d + x.M*m + x.Mp*mp + x.F*f) * deg2rad
+		k := eccentricity(x.M)
+		sl += x.L * k * math.Sin(arg)
+		sr += x.R * k * math.Cos(arg)
+	}
+	for _, x := range moonB {
+		arg := (x.D*d + x.M*m + x.Mp*mp + x.F*f) * deg2rad
+		sb += x.L * eccentricity(x.M) * math.Sin(arg)
+	}
+	lp, mp, f = lp*deg2rad, mp*deg2rad, f*deg2rad
+
+	// additive terms of the action of Venus, Jupiter and of the flattening of
+	// the earth
+	sl += 3958*math.Sin(a1) + 1962*math.Sin(lp-f) + 318*math.Sin(a2)
+	sb += -2235*math.Sin(lp) + 382*math.Sin(a3) + 175*math.Sin(a1-f) + 175*math.Sin(a1+f) + 127*math.Sin(lp-mp) - 115*math.Sin(lp+mp)
+
+	psi, _, _ := coord.Nutation(tt)
+	lon := lp*rad2deg + sl/1e6 + psi*rad2deg
+	return normalize(lon), sb / 1e6, 385000.56 + sr/1000
+}
+
+// moonLR and moonB are the periodic terms of the longitude (1e-6 degrees),
+// distance (meters) and latitude (1e-6 degrees) of the moon given as the
+// multipliers of the mean elongation, the mean anomaly of the sun, the mean
+// anomaly of the moon and the argument of latitude (Meeus, tables 47.A and
+// 47.B).
+var moonLR = []struct {
+	D, M, Mp, F float64
+	L, R        float64
+}{
+	{0, 0, 1, 0, 6288774, -20905355},
+	{2, 0, -1, 0, 1274027, -3699111},
+	{2, 0, 0, 0, 658314, -2955968},
+	{0, 0, 2, 0, 213618, -569925},
+	{0, 1, 0, 0, -185116, 48888},
+	{0, 0, 0, 2, -114332, -3149},
+	{2, 0, -2, 0, 58793, 246158},
+	{2, -1, -1, 0, 57066, -152138},
+	{2, 0, 1, 0, 53322, -170733},
+	{2, -1, 0, 0, 45758, -204586},
+	{0, 1, -1, 0, -40923, -129620},
+	{1, 0, 0, 0, -34720, 108743},
+	{0, 1, 1, 0, -30383, 104755},
+	{2, 0, 0, -2, 15327, 10321},
+	{0, 0, 1, 2, -12528, 0},
+	{0, 0, 1, -2, 10980, 79661},
+	{4, 0, -1, 0, 10675, -34782},
+	{0, 0, 3, 0, 10034, -23210},
+	{4, 0, -2, 0, 8548, -21636},
+	{2, 1, -1, 0, -7888, 24208},
+	{2, 1, 0, 0, -6766, 30824},
+	{1, 0, -1, 0, -5163, -8379},
+	{1, 1, 0, 0, 4987, -16675},
+	{2, -1, 1, 0, 4036, -12831},
+	{2, 0, 2, 0, 3994, -10445},
+	{4, 0, 0, 0, 3861, -11650},
+	{2, 0, -3, 0, 3665, 14403},
+	{0, 1, -2, 0, -2689, -7003},
+	{2, 0, -1, 2, -2602, 0},
+	{2, -1, -2, 0, 2390, 10056},
+	{1, 0, 1, 0, -2348, 6322},
+	{2, -2, 0, 0, 2236, -9884},
+	{0, 1, 2, 0, -2120, 5751},
+	{0, 2, 0, 0, -2069, 0},
+	{2, -2, -1, 0, 2048, -4950},
+	{2, 0, 1, -2, -1773, 4130},
+	{2, 0, 0, 2, -1595, 0},
+	{4, -1, -1, 0, 1215, -3958},
+	{0, 0, 2, 2, -1110, 0},
+	{3, 0, -1, 0, -892, 3258},
+	{2, 1, 1, 0, -810, 2616},
+	{4, -1, -2, 0, 759, -1897},
+	{0, 2, -1, 0, -713, -2117},
+	{2, 2, -1, 0, -700, 2354},
+	{2, 1, -2, 0, 691, 0},
+	{2, -1, 0, -2, 596, 0},
+	{4, 0, 1, 0, 549, -1423},
+	{0, 0, 4, 0, 537, -1117},
+	{4, -1, 0, 0, 520, -1571},
+	{1, 0, -2, 0, -487, -1739},
+	{2, 1, 0, -2, -399, 0},
+	{0, 0, 2, -2, -381, -4421},
+	{1, 1, 1, 0, 351, 0},
+	{3, 0, -2, 0, -340, 0},
+	{4, 0, -3, 0, 330, 0},
+	{2, -1, 2, 0, 327, 0},
+	{0, 2, 1, 0, -323, 1165},
+	{1, 1, -1, 0, 299, 0},
+	{2, 0, 3, 0, 294, 0},
+	{2, 0, -1, -2, 0, 8752},
+}
+
+var moonB = []struct {
+	D, M, Mp, F float64
+	L           float64
+}{
+	{0, 0, 0, 1, 5128122},
+	{0, 0, 1, 1, 280602},
+	{0, 0, 1, -1, 277693},
+	{2, 0, 0, -1, 173237},
+	{2, 0, -1, 1, 55413},
+	{2, 0, -1, -1, 46271},
+	{2, 0, 0, 1, 32573},
+	{0, 0, 2, 1, 17198},
+	{2, 0, 1, -1, 9266},
+	{0, 0, 2, -1, 8822},
+	{2, -1, 0, -1, 8216},
+	{2, 0, -2, -1, 4324},
+	{2, 0, 1, 1, 4200},
+	{2, 1, 0, -1, -3359},
+	{2, -1, -1, 1, 2463},
+	{2, -1, 0, 1, 2211},
+	{2, -1, -1, -1, 2065},
+	{0, 1, -1, -1, -1870},
+	{4, 0, -1, -1, 1828},
+	{0, 1, 0, 1, -1794},
+	{0, 0, 0, 3, -1749},
+	{0, 1, -1, 1, -1565},
+	{1, 0, 0, 1, -1491},
+	{0, 1, 1, 1, -1475},
+	{0, 1, 1, -1, -1410},
+	{0, 1, 0, -1, -1344},
+	{1, 0, 0, -1, -1335},
+	{0, 0, 3, 1, 1107},
+	{4, 0, 0, -1, 1021},
+	{4, 0, -1, 1, 833},
+	{0, 0, 1, -3, 777},
+	{4, 0, -2, 1, 671},
+	{2, 0, 0, -3, 607},
+	{2, 0, 2, -1, 596},
+	{2, -1, 1, -1, 491},
+	{2, 0, -2, 1, -451},
+	{0, 0, 3, -1, 439},
+	{2, 0, 2, 1, 422},
+	{2, 0, -3, -1, 421},
+	{2, 1, -1, 1, -366},
+	{2, 1, 0, 1, -351},
+	{4, 0, 0, 1, 331},
+	{2, -1, 1, 1, 315},
+	{2, -2, 0, -1, 302},
+	{0, 0, 1, 3, -283},
+	{2, 1, 1, -1, -229},
+	{1, 1, 0, -1, 223},
+	{1, 1, 0, 1, 223},
+	{0, 1, -2, -1, -220},
+	{2, 1, -1, -1, -220},
+	{1, 0, 1, 1, -185},
+	{2, -1, -2, -1, 181},
+	{0, 1, 2, 1, -177},
+	{4, 0, -2, -1, 176},
+	{4, -1, -1, -1, 166},
+	{1, 0, 1, -1, -164},
+	{4, 0, 1, -1, 132},
+	{1, 0, -1, -1, -119},
+	{4, -1, 0, -1, 115},
+	{2, -2, 0, 1, 107},
+}
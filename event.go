@@ -0,0 +1,158 @@
+package celest
+
+import (
+	"sort"
+	"time"
+)
+
+type Event struct {
+	Sid   int
+	Label string
+
+	// Entry and exit time of the event
+	Starts time.Time
+	Ends   time.Time
+
+	// The satellite was already in the area at the start of the period or was
+	// still in it at the end: Starts or Ends is then a bound of the period and
+	// not an entry or an exit.
+	StartsBefore bool
+	EndsAfter    bool
+}
+
+func (e Event) Duration() time.Duration {
+	return e.Ends.Sub(e.Starts)
+}
+
+// Crossings gives the entry and exit time of the satellite in the given shape
+// (labeled saa) and in each of the named areas over the period p. The points
+// of the trajectory are sampled every s and the entry and exit are refined
+// between two samples. The events truncated by the period are marked.
+func (e Element) Crossings(p, s time.Duration, saa Shape, areas ...Area) ([]*Event, error) {
+	g, err := e.propagator()
+	if err != nil {
+		return nil, err
+	}
+	defer g.Close()
+
+	if saa != nil {
+		areas = append([]Area{{Label: "saa", Shape: saa}}, areas...)
+	}
+	var es []*Event
+	for _, a := range areas {
+		contains := func(w float64) (bool, error) {
+			pt, err := g.At(w)
+			if err != nil {
+				return false, err
+			}
+			return a.Contains(*pt), nil
+		}
+		ws, err := e.windows(p, s, contains)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range ws {
+			v := Event{
+				Sid:    e.Sid,
+				Label:  a.Label,
+				Starts: g.Time(w.Starts),
+				Ends:   g.Time(w.Ends),
+
+				StartsBefore: w.StartsBefore,
+				EndsAfter:    w.EndsAfter,
+			}
+			es = append(es, &v)
+		}
+	}
+	sort.SliceStable(es, func(i, j int) bool { return es[i].Starts.Before(es[j].Starts) })
+	return es, nil
+}
+
+type window struct {
+	Starts float64
+	Ends   float64
+
+	StartsBefore bool
+	EndsAfter    bool
+}
+
+// windows gives the intervals (minutes since epoch) over the period p where f
+// is true. f is evaluated every s and each change of state is refined between
+// two samples. Intervals still open at the start or the end of the period are
+// bounded by the first or last sample and marked as such.
+func (e Element) windows(p, s time.Duration, f func(float64) (bool, error)) ([]window, error) {
+	var (
+		ws   []window
+		curr window
+		prev bool
+	)
+	delta := s.Seconds() / time.Minute.Seconds()
+	var when float64
+	if !e.Base.IsZero() {
+		delta := e.Base.Sub(e.When)
+		when = delta.Seconds() / time.Minute.Seconds()
+	}
+	for elapsed := time.Duration(0); elapsed < p; elapsed += s {
+		ok, err := f(when)
+		if err != nil {
+			return ws, err
+		}
+		switch {
+		case elapsed == 0 && ok:
+			curr = window{Starts: when, StartsBefore: true}
+		case elapsed > 0 && ok != prev:
+			w, err := locate(f, when-delta, when)
+			if err != nil {
+				return ws, err
+			}
+			if ok {
+				curr = window{Starts: w}
+			} else {
+				curr.Ends = w
+				ws = append(ws, curr)
+			}
+		}
+		prev = ok
+		when += delta
+	}
+	if prev {
+		curr.Ends, curr.EndsAfter = when-delta, true
+		ws = append(ws, curr)
+	}
+	return ws, nil
+}
+
+// mergeEvents appends vs to es. An event of vs starting with the period is
+// merged with the last event of es having the same label when this one ends
+// with its period less than s before (events split between two consecutive
+// TLE).
+func mergeEvents(es, vs []*Event, s time.Duration) []*Event {
+	n := len(es)
+	for _, v := range vs {
+		var last *Event
+		for i := n - 1; i >= 0; i-- {
+			if es[i].Label == v.Label {
+				last = es[i]
+				break
+			}
+		}
+		if last != nil && last.EndsAfter && v.StartsBefore && v.Starts.Sub(last.Ends) <= s && !v.Starts.Before(last.Ends) {
+			last.Ends, last.EndsAfter = v.Ends, v.EndsAfter
+			continue
+		}
+		es = append(es, v)
+	}
+	return es
+}
+
+// completeEvents gives the events of es having both their entry and their
+// exit within the period.
+func completeEvents(es []*Event) []*Event {
+	vs := es[:0]
+	for _, e := range es {
+		if !e.StartsBefore && !e.EndsAfter {
+			vs = append(vs, e)
+		}
+	}
+	return vs
+}
@@ -0,0 +1,386 @@
+package celest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the files accepted by Trajectory.Scan.
+const (
+	FormatTLE  = "tle"
+	FormatXML  = "xml"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatKVN  = "kvn"
+)
+
+var epochLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z",
+	"2006-002T15:04:05.999999999",
+	"2006-002T15:04:05.999999999Z",
+}
+
+type OMMError struct {
+	key   string
+	cause error
+}
+
+func (e *OMMError) Error() string {
+	return fmt.Sprintf("fail to parse OMM keyword %s: %v", e.key, e.cause)
+}
+
+// OMM is a CCSDS Orbit Mean-elements Message giving the SGP4 mean elements of
+// a satellite as published by CelesTrak and Space-Track. Angles are given in
+// degrees and the mean motion in revolutions per day.
+type OMM struct {
+	Name   string
+	Id     string
+	Center string
+	Frame  string
+	System string
+	Theory string
+
+	Epoch        time.Time
+	Motion       float64
+	Excentricity float64
+	Inclination  float64
+	Ascension    float64
+	Perigee      float64
+	Anomaly      float64
+
+	Ephemeris  int
+	Class      string
+	Sid        int
+	Set        int
+	Revolution int
+	BStar      float64
+	Mean1      float64
+	Mean2      float64
+
+	// First error met while decoding the keywords of the message
+	Err error
+}
+
+// Element gives the element to be used by SGP4 from the mean elements of the
+// message. Its TLE is left empty when the catalog number can not be encoded in
+// the Alpha-5 scheme.
+func (o OMM) Element() (*Element, error) {
+	if o.Err != nil {
+		return nil, o.Err
+	}
+	if o.Epoch.IsZero() {
+		return nil, &OMMError{key: "EPOCH", cause: fmt.Errorf("missing")}
+	}
+	if o.Motion <= 0 {
+		return nil, &OMMError{key: "MEAN_MOTION", cause: fmt.Errorf("missing")}
+	}
+	switch strings.ToUpper(o.Theory) {
+	case "", "SGP4", "SGP/SGP4":
+	default:
+		return nil, &OMMError{key: "MEAN_ELEMENT_THEORY", cause: fmt.Errorf("unsupported %s", o.Theory)}
+	}
+	// SGP4 is only valid for elements given in TEME and UTC
+	if o.Frame != "" && !strings.EqualFold(o.Frame, "TEME") {
+		return nil, &OMMError{key: "REF_FRAME", cause: fmt.Errorf("unsupported %s", o.Frame)}
+	}
+	if o.System != "" && !strings.EqualFold(o.System, "UTC") {
+		return nil, &OMMError{key: "TIME_SYSTEM", cause: fmt.Errorf("unsupported %s", o.System)}
+	}
+	year := o.Epoch.Year()
+	doy := o.Epoch.Sub(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)).Hours()/24 + 1
+
+	e := Element{
+		Sid:          o.Sid,
+		Name:         o.Name,
+		Class:        o.Class,
+		Designator:   designator(o.Id),
+		Year:         year % 100,
+		Doy:          doy,
+		Mean1:        o.Mean1 / (xpdotp * minPerDays),
+		Mean2:        o.Mean2 / (xpdotp * minPerDays * minPerDays),
+		BStar:        o.BStar,
+		Ephemeris:    o.Ephemeris,
+		Set:          o.Set,
+		Inclination:  o.Inclination * deg2rad,
+		Ascension:    o.Ascension * deg2rad,
+		Excentricity: o.Excentricity,
+		Perigee:      o.Perigee * deg2rad,
+		Anomaly:      o.Anomaly * deg2rad,
+		Motion:       o.Motion / xpdotp,
+		Revolution:   o.Revolution,
+	}
+	e.setEpoch(year, doy)
+	if rs, err := e.Format(); err == nil {
+		e.TLE = rs
+	}
+	return &e, nil
+}
+
+// designator gives the international designator of a TLE (98067A) from the
+// one of an OMM (1998-067A).
+func designator(id string) string {
+	if len(id) < 9 || id[4] != '-' {
+		return id
+	}
+	return id[2:4] + id[5:]
+}
+
+// set decodes the keyword key of the message. The first error is also kept in
+// o.Err so that the other messages of a file can still be read.
+func (o *OMM) set(key, value string) error {
+	if ix := strings.Index(value, "["); ix >= 0 {
+		value = value[:ix]
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	var err error
+	switch key = strings.ToUpper(strings.TrimSpace(key)); key {
+	case "OBJECT_NAME":
+		o.Name = value
+	case "OBJECT_ID":
+		o.Id = value
+	case "CENTER_NAME":
+		o.Center = value
+	case "REF_FRAME":
+		o.Frame = value
+	case "TIME_SYSTEM":
+		o.System = value
+	case "MEAN_ELEMENT_THEORY":
+		o.Theory = value
+	case "EPOCH":
+		o.Epoch, err = parseEpoch(value)
+	case "MEAN_MOTION":
+		o.Motion, err = strconv.ParseFloat(value, 64)
+	case "ECCENTRICITY":
+		o.Excentricity, err = strconv.ParseFloat(value, 64)
+	case "INCLINATION":
+		o.Inclination, err = strconv.ParseFloat(value, 64)
+	case "RA_OF_ASC_NODE":
+		o.Ascension, err = strconv.ParseFloat(value, 64)
+	case "ARG_OF_PERICENTER":
+		o.Perigee, err = strconv.ParseFloat(value, 64)
+	case "MEAN_ANOMALY":
+		o.Anomaly, err = strconv.ParseFloat(value, 64)
+	case "EPHEMERIS_TYPE":
+		o.Ephemeris, err = strconv.Atoi(value)
+	case "CLASSIFICATION_TYPE":
+		o.Class = value
+	case "NORAD_CAT_ID":
+		o.Sid, err = ParseCatalog(value)
+	case "ELEMENT_SET_NO":
+		o.Set, err = strconv.Atoi(value)
+	case "REV_AT_EPOCH":
+		o.Revolution, err = strconv.Atoi(value)
+	case "BSTAR":
+		o.BStar, err = strconv.ParseFloat(value, 64)
+	case "MEAN_MOTION_DOT":
+		o.Mean1, err = strconv.ParseFloat(value, 64)
+	case "MEAN_MOTION_DDOT":
+		o.Mean2, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil {
+		err = &OMMError{key: key, cause: err}
+		if o.Err == nil {
+			o.Err = err
+		}
+	}
+	return err
+}
+
+func parseEpoch(str string) (time.Time, error) {
+	var err error
+	for _, f := range epochLayouts {
+		var w time.Time
+		if w, err = time.Parse(f, str); err == nil {
+			return w, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// DetectFormat guesses the format of a file of elements (TLE or OMM in XML,
+// JSON, CSV or KVN) from its first bytes.
+func DetectFormat(buf []byte) string {
+	buf = bytes.TrimLeft(buf, "\ufeff \t\r\n")
+	if len(buf) == 0 {
+		return FormatTLE
+	}
+	switch buf[0] {
+	case '<':
+		return FormatXML
+	case '{', '[':
+		return FormatJSON
+	}
+	if bytes.Contains(buf, []byte("CCSDS_OMM_VERS")) {
+		return FormatKVN
+	}
+	line := buf
+	if ix := bytes.IndexByte(line, '\n'); ix >= 0 {
+		line = line[:ix]
+	}
+	if bytes.Contains(line, []byte(",")) && bytes.Contains(line, []byte("EPOCH")) {
+		return FormatCSV
+	}
+	return FormatTLE
+}
+
+// ParseOMM decodes the messages of buf in the given format (xml, json, csv or
+// kvn). The format is detected from the content of buf when empty. An error is
+// only returned when the document itself is malformed: the error of a keyword
+// is given by the Err field of its message.
+func ParseOMM(buf []byte, format string) ([]OMM, error) {
+	buf = bytes.TrimPrefix(buf, []byte("\xef\xbb\xbf"))
+	if format == "" {
+		format = DetectFormat(buf)
+	}
+	switch strings.ToLower(format) {
+	case FormatXML:
+		return parseXML(buf)
+	case FormatJSON:
+		return parseJSON(buf)
+	case FormatCSV:
+		return parseCSV(buf)
+	case FormatKVN:
+		return parseKVN(buf)
+	default:
+		return nil, fmt.Errorf("unsupported OMM format %s", format)
+	}
+}
+
+// parseXML decodes the omm elements of a NDM document (or of a single OMM
+// document). The keywords are the names of the leaf elements.
+func parseXML(buf []byte) ([]OMM, error) {
+	var (
+		rs   = xml.NewDecoder(bytes.NewReader(buf))
+		ms   []OMM
+		curr *OMM
+		text []byte
+	)
+	for {
+		tok, err := rs.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Local == "omm" {
+				curr = new(OMM)
+			}
+			text = text[:0]
+		case xml.CharData:
+			text = append(text, tok...)
+		case xml.EndElement:
+			if curr == nil {
+				break
+			}
+			if tok.Name.Local == "omm" {
+				ms, curr = append(ms, *curr), nil
+				break
+			}
+			curr.set(tok.Name.Local, string(text))
+			text = text[:0]
+		}
+	}
+	return ms, nil
+}
+
+func parseJSON(buf []byte) ([]OMM, error) {
+	var vs []map[string]interface{}
+	if bytes.HasPrefix(bytes.TrimSpace(buf), []byte("{")) {
+		buf = append(append([]byte("["), buf...), ']')
+	}
+	rs := json.NewDecoder(bytes.NewReader(buf))
+	rs.UseNumber()
+	if err := rs.Decode(&vs); err != nil {
+		return nil, err
+	}
+	ms := make([]OMM, 0, len(vs))
+	for _, v := range vs {
+		var o OMM
+		for k, v := range v {
+			if v == nil {
+				continue
+			}
+			o.set(k, fmt.Sprint(v))
+		}
+		ms = append(ms, o)
+	}
+	return ms, nil
+}
+
+func parseCSV(buf []byte) ([]OMM, error) {
+	rs := csv.NewReader(bytes.NewReader(buf))
+	rs.FieldsPerRecord = -1
+	head, err := rs.Read()
+	if err != nil {
+		return nil, err
+	}
+	var ms []OMM
+	for {
+		row, err := rs.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var o OMM
+		for i, v := range row {
+			if i < len(head) {
+				o.set(head[i], v)
+			}
+		}
+		ms = append(ms, o)
+	}
+	return ms, nil
+}
+
+// parseKVN decodes messages in the Keyword=Value notation. Each message starts
+// with the CCSDS_OMM_VERS keyword.
+func parseKVN(buf []byte) ([]OMM, error) {
+	var (
+		ms   []OMM
+		curr *OMM
+		rs   = bufio.NewScanner(bytes.NewReader(buf))
+	)
+	for rs.Scan() {
+		line := strings.TrimSpace(rs.Text())
+		if line == "" || strings.HasPrefix(line, "COMMENT") {
+			continue
+		}
+		ix := strings.Index(line, "=")
+		if ix < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:ix]), line[ix+1:]
+		if key == "CCSDS_OMM_VERS" {
+			if curr != nil {
+				ms = append(ms, *curr)
+			}
+			curr = new(OMM)
+			continue
+		}
+		if curr == nil {
+			continue
+		}
+		curr.set(key, value)
+	}
+	if curr != nil {
+		ms = append(ms, *curr)
+	}
+	return ms, rs.Err()
+}
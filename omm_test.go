@@ -0,0 +1,27 @@
+package celest
+
+import (
+	"fmt"
+)
+
+func ExampleParseOMM() {
+	const doc = "\xef\xbb\xbf" + `[
+{"OBJECT_NAME":"ISS (ZARYA)","OBJECT_ID":"1998-067A","EPOCH":"2018-10-31T08:37:20.838144","MEAN_MOTION":15.53880871,"ECCENTRICITY":0.0004268,"INCLINATION":51.642,"RA_OF_ASC_NODE":60.1332,"ARG_OF_PERICENTER":356.0118,"MEAN_ANOMALY":61.1534,"EPHEMERIS_TYPE":0,"CLASSIFICATION_TYPE":"U","NORAD_CAT_ID":25544,"ELEMENT_SET_NO":999,"REV_AT_EPOCH":13969,"BSTAR":2.5703e-5,"MEAN_MOTION_DOT":1.207e-5,"MEAN_MOTION_DDOT":0},
+{"OBJECT_NAME":"BROKEN","EPOCH":"2018-10-31T08:37:20.838144","MEAN_MOTION":"fast","NORAD_CAT_ID":25545},
+{"OBJECT_NAME":"GCRF","REF_FRAME":"GCRF","TIME_SYSTEM":"UTC","EPOCH":"2018-10-31T08:37:20.838144","MEAN_MOTION":15.5,"NORAD_CAT_ID":25546}
+]`
+	ms, err := ParseOMM([]byte(doc), "")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, o := range ms {
+		_, err := o.Element()
+		fmt.Printf("%d %s: %v", o.Sid, o.Name, err)
+		fmt.Println()
+	}
+	// Output:
+	// 25544 ISS (ZARYA): <nil>
+	// 25545 BROKEN: fail to parse OMM keyword MEAN_MOTION: strconv.ParseFloat: parsing "fast": invalid syntax
+	// 25546 GCRF: fail to parse OMM keyword REF_FRAME: unsupported GCRF
+}
@@ -0,0 +1,67 @@
+package celest
+
+import (
+	"math"
+)
+
+// precision of the refinement between two samples (one millisecond expressed
+// in minutes)
+const precision = 0.001 / secPerMins
+
+const golden = 0.6180339887498949
+
+// locate looks for the instant (minutes since epoch) between lo and hi where
+// the state given by f changes. f(lo) and f(hi) are expected to differ.
+func locate(f func(float64) (bool, error), lo, hi float64) (float64, error) {
+	first, err := f(lo)
+	if err != nil {
+		return lo, err
+	}
+	for hi-lo > precision {
+		mid := (lo + hi) / 2
+		ok, err := f(mid)
+		if err != nil {
+			return mid, err
+		}
+		if ok == first {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// minimize looks for the instant (minutes since epoch) between lo and hi where
+// f reaches its minimum with a golden section search. It returns the instant
+// and the value of f at that instant.
+func minimize(f func(float64) (float64, error), lo, hi float64) (float64, float64, error) {
+	x1 := hi - golden*(hi-lo)
+	x2 := lo + golden*(hi-lo)
+	f1, err := f(x1)
+	if err != nil {
+		return x1, f1, err
+	}
+	f2, err := f(x2)
+	if err != nil {
+		return x2, f2, err
+	}
+	for math.Abs(hi-lo) > precision {
+		if f1 < f2 {
+			hi, x2, f2 = x2, x1, f1
+			x1 = hi - golden*(hi-lo)
+			if f1, err = f(x1); err != nil {
+				return x1, f1, err
+			}
+		} else {
+			lo, x1, f1 = x1, x2, f2
+			x2 = lo + golden*(hi-lo)
+			if f2, err = f(x2); err != nil {
+				return x2, f2, err
+			}
+		}
+	}
+	x := (lo + hi) / 2
+	v, err := f(x)
+	return x, v, err
+}
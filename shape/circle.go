@@ -0,0 +1,17 @@
+package shape
+
+import (
+	"github.com/busoc/inspect/coord"
+)
+
+// Circle is the set of points within Radius kilometers (geodesic distance on
+// the ellipsoid) of its centre.
+type Circle struct {
+	Lat    float64
+	Lon    float64
+	Radius float64
+}
+
+func (c Circle) Contains(lat, lon float64) bool {
+	return coord.Distance(c.Lat, c.Lon, lat, lon) <= c.Radius
+}
@@ -0,0 +1,225 @@
+package shape
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Load reads a polygon or multipolygon from a file. The content of the file
+// can be GeoJSON or WKT.
+func Load(file string) (MultiPolygon, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(buf)
+}
+
+// Parse decodes a polygon or multipolygon given as GeoJSON or WKT.
+func Parse(buf []byte) (MultiPolygon, error) {
+	buf = bytes.TrimSpace(buf)
+	if len(buf) > 0 && buf[0] == '{' {
+		return ParseGeoJSON(buf)
+	}
+	return ParseWKT(string(buf))
+}
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geometry       `json:"geometry"`
+	Geometries  []geometry      `json:"geometries"`
+	Features    []geometry      `json:"features"`
+}
+
+// ParseGeoJSON decodes a GeoJSON Polygon or MultiPolygon. When a Feature,
+// a FeatureCollection or a GeometryCollection is given, all their polygons
+// are merged.
+func ParseGeoJSON(buf []byte) (MultiPolygon, error) {
+	var g geometry
+	if err := json.Unmarshal(buf, &g); err != nil {
+		return nil, err
+	}
+	return g.polygons()
+}
+
+func (g geometry) polygons() (MultiPolygon, error) {
+	var (
+		mp  MultiPolygon
+		err error
+	)
+	switch g.Type {
+	case "Polygon":
+		var cs [][][]float64
+		if err = json.Unmarshal(g.Coordinates, &cs); err == nil {
+			var p Polygon
+			p, err = toPolygon(cs)
+			mp = append(mp, p)
+		}
+	case "MultiPolygon":
+		var cs [][][][]float64
+		if err = json.Unmarshal(g.Coordinates, &cs); err == nil {
+			for _, c := range cs {
+				p, err := toPolygon(c)
+				if err != nil {
+					return nil, err
+				}
+				mp = append(mp, p)
+			}
+		}
+	case "Feature":
+		if g.Geometry == nil {
+			return nil, fmt.Errorf("geojson: feature without geometry")
+		}
+		return g.Geometry.polygons()
+	case "FeatureCollection", "GeometryCollection":
+		gs := g.Features
+		if g.Type == "GeometryCollection" {
+			gs = g.Geometries
+		}
+		for _, g := range gs {
+			ps, err := g.polygons()
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, ps...)
+		}
+	default:
+		err = fmt.Errorf("geojson: unsupported type %q", g.Type)
+	}
+	return mp, err
+}
+
+func toPolygon(cs [][][]float64) (Polygon, error) {
+	p := make(Polygon, 0, len(cs))
+	for _, c := range cs {
+		r := make(Ring, 0, len(c))
+		for _, v := range c {
+			if len(v) < 2 {
+				return nil, fmt.Errorf("geojson: invalid position %v", v)
+			}
+			r = append(r, LatLon{Lat: v[1], Lon: v[0]})
+		}
+		p = append(p, r)
+	}
+	return p, nil
+}
+
+// ParseWKT decodes a WKT POLYGON or MULTIPOLYGON.
+func ParseWKT(str string) (MultiPolygon, error) {
+	str = strings.TrimSpace(str)
+	ix := strings.IndexByte(str, '(')
+	if ix < 0 {
+		return nil, fmt.Errorf("wkt: missing coordinates")
+	}
+	kind := strings.ToUpper(strings.TrimSpace(str[:ix]))
+	if i := strings.IndexFunc(kind, unicode.IsSpace); i >= 0 {
+		// drop dimension (Z, M, ZM) given after the geometry type
+		kind = kind[:i]
+	}
+	vs, rest, err := parseList(str[ix:])
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("wkt: unexpected %q", rest)
+	}
+	var mp MultiPolygon
+	switch kind {
+	case "POLYGON":
+		p, err := wktPolygon(vs)
+		if err != nil {
+			return nil, err
+		}
+		mp = append(mp, p)
+	case "MULTIPOLYGON":
+		for _, v := range vs.list {
+			p, err := wktPolygon(v)
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, p)
+		}
+	default:
+		return nil, fmt.Errorf("wkt: unsupported type %q", kind)
+	}
+	return mp, nil
+}
+
+// node is either a list of nodes or a position
+type node struct {
+	list []node
+	pos  []float64
+}
+
+func parseList(str string) (node, string, error) {
+	var n node
+	if str == "" || str[0] != '(' {
+		return n, str, fmt.Errorf("wkt: expected '('")
+	}
+	str = strings.TrimSpace(str[1:])
+	for {
+		var (
+			v   node
+			err error
+		)
+		if strings.HasPrefix(str, "(") {
+			v, str, err = parseList(str)
+		} else {
+			v, str, err = parsePosition(str)
+		}
+		if err != nil {
+			return n, str, err
+		}
+		n.list = append(n.list, v)
+
+		str = strings.TrimSpace(str)
+		switch {
+		case strings.HasPrefix(str, ","):
+			str = strings.TrimSpace(str[1:])
+		case strings.HasPrefix(str, ")"):
+			return n, str[1:], nil
+		default:
+			return n, str, fmt.Errorf("wkt: expected ',' or ')'")
+		}
+	}
+}
+
+func parsePosition(str string) (node, string, error) {
+	var n node
+	ix := strings.IndexAny(str, ",)")
+	if ix < 0 {
+		return n, str, fmt.Errorf("wkt: unterminated position")
+	}
+	for _, f := range strings.Fields(str[:ix]) {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return n, str, fmt.Errorf("wkt: invalid number %q", f)
+		}
+		n.pos = append(n.pos, v)
+	}
+	if len(n.pos) < 2 {
+		return n, str, fmt.Errorf("wkt: invalid position %q", str[:ix])
+	}
+	return n, str[ix:], nil
+}
+
+func wktPolygon(n node) (Polygon, error) {
+	p := make(Polygon, 0, len(n.list))
+	for _, rs := range n.list {
+		r := make(Ring, 0, len(rs.list))
+		for _, v := range rs.list {
+			if v.pos == nil {
+				return nil, fmt.Errorf("wkt: invalid polygon")
+			}
+			r = append(r, LatLon{Lat: v.pos[1], Lon: v.pos[0]})
+		}
+		p = append(p, r)
+	}
+	return p, nil
+}
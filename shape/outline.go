@@ -0,0 +1,56 @@
+package shape
+
+import (
+	"math"
+
+	"github.com/busoc/inspect/coord"
+)
+
+// Ring gives the outline of the rectangle. Its parallels are sampled every
+// degree so that they are not drawn as great circle arcs.
+func (r Rect) Ring() Ring {
+	east := r.East
+	if r.West > east {
+		east += 360
+	}
+	var (
+		ns = int(math.Ceil(east-r.West)) + 1
+		rs = make(Ring, 0, 2*ns+1)
+	)
+	for i := 0; i < ns; i++ {
+		lon := r.West + (east-r.West)*float64(i)/float64(ns-1)
+		rs = append(rs, LatLon{Lat: r.South, Lon: math.Remainder(lon, 360)})
+	}
+	for i := ns - 1; i >= 0; i-- {
+		lon := r.West + (east-r.West)*float64(i)/float64(ns-1)
+		rs = append(rs, LatLon{Lat: r.North, Lon: math.Remainder(lon, 360)})
+	}
+	return append(rs, rs[0])
+}
+
+// Ring gives the outline of the circle approximated by n vertices.
+func (c Circle) Ring(n int) Ring {
+	if n < 3 {
+		n = 3
+	}
+	rs := make(Ring, 0, n+1)
+	for i := 0; i < n; i++ {
+		lat, lon := coord.Destination(c.Lat, c.Lon, 360*float64(i)/float64(n), c.Radius)
+		rs = append(rs, LatLon{Lat: lat, Lon: lon})
+	}
+	return append(rs, rs[0])
+}
+
+// Antimeridian gives the latitude at which the segment between two points
+// (degrees) crosses the antimeridian and the longitude (±180) on the side of
+// the first point. It reports false when the longitudes of the points are less
+// than 180° apart.
+func Antimeridian(lat0, lon0, lat1, lon1 float64) (float64, float64, bool) {
+	if math.Abs(lon1-lon0) <= 180 {
+		return 0, 0, false
+	}
+	edge := math.Copysign(180, lon0)
+	lon := lon1 + math.Copysign(360, lon0)
+	lat := lat0 + (lat1-lat0)*(edge-lon0)/(lon-lon0)
+	return lat, edge, true
+}
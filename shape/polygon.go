@@ -0,0 +1,110 @@
+package shape
+
+import (
+	"math"
+)
+
+const deg2rad = math.Pi / 180
+
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+// Ring is a closed sequence of vertices connected by great circle arcs. The
+// last vertex can be equal to the first one.
+type Ring []LatLon
+
+// Contains reports whether the given point (degrees) is inside the ring. The
+// edges are followed on the sphere so that a ring can cross the antimeridian
+// or surround a pole. The inside of the ring is the side of its centroid.
+func (r Ring) Contains(lat, lon float64) bool {
+	if len(r) < 3 {
+		return false
+	}
+	sum, ok := r.winding(lat, lon)
+	if ok {
+		return true
+	}
+	if math.Abs(sum) < math.Pi {
+		return false
+	}
+	if c, ok := r.winding(r.centroid()); !ok && math.Abs(c) > math.Pi {
+		return math.Signbit(c) == math.Signbit(sum)
+	}
+	return true
+}
+
+// winding gives the total angle swept by the edges of the ring as seen from
+// the given point. It is close to ±2π when the ring surrounds the point and
+// close to zero otherwise. The boolean is true when the point is a vertex.
+func (r Ring) winding(lat, lon float64) (float64, bool) {
+	var sum, prev float64
+	for i := 0; i <= len(r); i++ {
+		v := r[i%len(r)]
+		if v.Lat == lat && math.Mod(v.Lon-lon, 360) == 0 {
+			return 0, true
+		}
+		b := bearing(lat, lon, v.Lat, v.Lon)
+		if i > 0 {
+			delta := b - prev
+			switch {
+			case delta > math.Pi:
+				delta -= 2 * math.Pi
+			case delta < -math.Pi:
+				delta += 2 * math.Pi
+			}
+			sum += delta
+		}
+		prev = b
+	}
+	return sum, false
+}
+
+func (r Ring) centroid() (float64, float64) {
+	var x, y, z float64
+	for _, v := range r {
+		lat, lon := v.Lat*deg2rad, v.Lon*deg2rad
+		x += math.Cos(lat) * math.Cos(lon)
+		y += math.Cos(lat) * math.Sin(lon)
+		z += math.Sin(lat)
+	}
+	return math.Atan2(z, math.Hypot(x, y)) / deg2rad, math.Atan2(y, x) / deg2rad
+}
+
+// Polygon is made of an outer ring and of zero or more holes.
+type Polygon []Ring
+
+func (p Polygon) Contains(lat, lon float64) bool {
+	if len(p) == 0 || !p[0].Contains(lat, lon) {
+		return false
+	}
+	for _, r := range p[1:] {
+		if r.Contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+type MultiPolygon []Polygon
+
+func (m MultiPolygon) Contains(lat, lon float64) bool {
+	for _, p := range m {
+		if p.Contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// bearing gives the initial bearing (radians) of the great circle going from
+// the first point to the second point.
+func bearing(lat0, lon0, lat1, lon1 float64) float64 {
+	lat0, lat1 = lat0*deg2rad, lat1*deg2rad
+	delta := (lon1 - lon0) * deg2rad
+
+	y := math.Sin(delta) * math.Cos(lat1)
+	x := math.Cos(lat0)*math.Sin(lat1) - math.Sin(lat0)*math.Cos(lat1)*math.Cos(delta)
+	return math.Atan2(y, x)
+}
@@ -0,0 +1,25 @@
+package shape
+
+// Surface is an area on the surface of the earth.
+type Surface interface {
+	Contains(lat, lon float64) bool
+}
+
+// Rect is an area bounded by two parallels and two meridians (degrees). When
+// West is greater than East, the area crosses the antimeridian.
+type Rect struct {
+	North float64
+	South float64
+	West  float64
+	East  float64
+}
+
+func (r Rect) Contains(lat, lon float64) bool {
+	if lat <= r.South || lat >= r.North {
+		return false
+	}
+	if r.West > r.East {
+		return lon > r.West || lon < r.East
+	}
+	return lon > r.West && lon < r.East
+}
@@ -0,0 +1,28 @@
+package shape
+
+import (
+	"fmt"
+)
+
+func ExampleParseWKT() {
+	mp, err := ParseWKT("POLYGON ((170 -10, -170 -10, -170 10, 170 10, 170 -10))")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(mp.Contains(0, 180), mp.Contains(0, 175), mp.Contains(0, 0))
+	// Output:
+	// true true false
+}
+
+func ExampleParseGeoJSON() {
+	str := `{"type": "Polygon", "coordinates": [[[-80, -60], [40, -60], [40, -5], [-80, -5], [-80, -60]], [[-10, -30], [0, -30], [0, -20], [-10, -20]]]}`
+	mp, err := ParseGeoJSON([]byte(str))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(mp.Contains(-30, -40), mp.Contains(-25, -5), mp.Contains(10, -40))
+	// Output:
+	// true false false
+}
@@ -0,0 +1,43 @@
+package shape
+
+// Range is a range of altitude (km). A zero Max means that the range has no
+// upper bound.
+type Range struct {
+	Min float64
+	Max float64
+}
+
+func (r Range) Contains(alt float64) bool {
+	return alt >= r.Min && (r.Max <= 0 || alt <= r.Max)
+}
+
+// Layer is the footprint of a volume starting at the given altitude (km).
+type Layer struct {
+	Alt float64
+	Surface
+}
+
+// Stack is a volume whose footprint changes with the altitude. The footprint
+// used for a point is the one of the highest layer below its altitude. Layers
+// are expected to be sorted by altitude.
+type Stack []Layer
+
+func (s Stack) Within(lat, lon, alt float64) bool {
+	for i := len(s) - 1; i >= 0; i-- {
+		if alt >= s[i].Alt {
+			return s[i].Contains(lat, lon)
+		}
+	}
+	return false
+}
+
+// SAA is a coarse model of the South Atlantic Anomaly whose footprint grows with
+// the altitude. Below 200km, the satellite is considered outside the anomaly.
+var SAA = Stack{
+	{Alt: 200, Surface: Rect{North: -20, South: -40, West: -65, East: -15}},
+	{Alt: 300, Surface: Rect{North: -15, South: -45, West: -70, East: 0}},
+	{Alt: 400, Surface: Rect{North: -10, South: -50, West: -80, East: 10}},
+	{Alt: 500, Surface: Rect{North: -5, South: -55, West: -85, East: 20}},
+	{Alt: 600, Surface: Rect{North: 0, South: -60, West: -90, East: 30}},
+	{Alt: 800, Surface: Rect{North: 5, South: -65, West: -95, East: 40}},
+}
@@ -0,0 +1,138 @@
+package celest
+
+import (
+	"math"
+	"time"
+
+	"github.com/busoc/inspect/coord"
+)
+
+type Station struct {
+	Label string
+
+	// Geodetic position of the station (degrees and kilometers)
+	Lat float64
+	Lon float64
+	Alt float64
+
+	// Minimum elevation (degrees) above which the satellite is visible
+	Elevation float64
+}
+
+// Look gives the azimuth, elevation (degrees) and range (kilometers) of the
+// satellite at the given point (TEME) as seen from the station.
+func (s Station) Look(p Point) (float64, float64, float64) {
+	x, y, z := p.toECEF()
+	sx, sy, sz := coord.GeodeticToECEF(s.Lat, s.Lon, s.Alt)
+	x, y, z = x-sx, y-sy, z-sz
+
+	lat, lon := s.Lat*deg2rad, s.Lon*deg2rad
+	slat, clat := math.Sin(lat), math.Cos(lat)
+	slon, clon := math.Sin(lon), math.Cos(lon)
+
+	east := -slon*x + clon*y
+	north := -slat*clon*x - slat*slon*y + clat*z
+	up := clat*clon*x + clat*slon*y + slat*z
+
+	dist := math.Sqrt(x*x + y*y + z*z)
+	az := math.Atan2(east, north) * rad2deg
+	if az < 0 {
+		az += 360
+	}
+	return az, math.Asin(up/dist) * rad2deg, dist
+}
+
+type Pass struct {
+	Sid   int
+	Label string
+
+	// Acquisition of signal, time of closest approach and loss of signal
+	AOS time.Time
+	TCA time.Time
+	LOS time.Time
+
+	// Azimuth (degrees) of the satellite at AOS, TCA and LOS
+	AzimuthAOS float64
+	Azimuth    float64
+	AzimuthLOS float64
+
+	// Maximum elevation (degrees) and range (kilometers) at TCA
+	Elevation float64
+	Range     float64
+
+	// The satellite was already visible at the start of the period or was
+	// still visible at the end (see Event).
+	StartsBefore bool
+	EndsAfter    bool
+}
+
+func (p Pass) Duration() time.Duration {
+	return p.LOS.Sub(p.AOS)
+}
+
+// Passes gives the windows of visibility of the satellite from the station
+// over a period of time. The points of the trajectory are sampled every s and
+// the AOS, TCA and LOS are refined between two samples. The passes truncated by
+// the period are marked.
+func (e Element) Passes(st Station, p, s time.Duration) ([]*Pass, error) {
+	g, err := e.propagator()
+	if err != nil {
+		return nil, err
+	}
+	defer g.Close()
+
+	look := func(w float64) (float64, float64, float64, error) {
+		pt, err := g.At(w)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		az, el, dist := st.Look(*pt)
+		return az, el, dist, nil
+	}
+	visible := func(w float64) (bool, error) {
+		_, el, _, err := look(w)
+		return el >= st.Elevation, err
+	}
+	elevation := func(w float64) (float64, error) {
+		_, el, _, err := look(w)
+		return -el, err
+	}
+	build := func(aos, los float64) (*Pass, error) {
+		tca, _, err := minimize(elevation, aos, los)
+		if err != nil {
+			return nil, err
+		}
+		x := Pass{
+			Sid:   e.Sid,
+			Label: st.Label,
+			AOS:   g.Time(aos),
+			TCA:   g.Time(tca),
+			LOS:   g.Time(los),
+		}
+		if x.AzimuthAOS, _, _, err = look(aos); err != nil {
+			return nil, err
+		}
+		if x.Azimuth, x.Elevation, x.Range, err = look(tca); err != nil {
+			return nil, err
+		}
+		if x.AzimuthLOS, _, _, err = look(los); err != nil {
+			return nil, err
+		}
+		return &x, nil
+	}
+
+	ws, err := e.windows(p, s, visible)
+	if err != nil {
+		return nil, err
+	}
+	ps := make([]*Pass, 0, len(ws))
+	for _, w := range ws {
+		x, err := build(w.Starts, w.Ends)
+		if err != nil {
+			return ps, err
+		}
+		x.StartsBefore, x.EndsAfter = w.StartsBefore, w.EndsAfter
+		ps = append(ps, x)
+	}
+	return ps, nil
+}
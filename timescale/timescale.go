@@ -0,0 +1,173 @@
+// Package timescale converts the times between the time scales UTC, TAI, TT,
+// GPS and UT1 and gives their julian, modified julian and CNES julian
+// representations.
+//
+// A time of a scale is a time.Time giving the reading of a clock of that scale
+// (its location is ignored). TAI-UTC is given by the table of leap seconds and
+// UT1-UTC by the earth orientation parameters of eop.Current.
+package timescale
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/busoc/inspect/coord"
+	"github.com/busoc/inspect/eop"
+)
+
+const (
+	deltaJD50 = 2433282.5
+
+	// TT-TAI and TAI-GPS (seconds)
+	deltaTT  = 32.184
+	deltaGPS = 19.0
+)
+
+// Scale is a time scale.
+type Scale uint8
+
+const (
+	UTC Scale = iota
+	TAI
+	TT
+	GPS
+	UT1
+)
+
+// Parse gives the scale named str (case insensitive). An empty string is UTC.
+func Parse(str string) (Scale, error) {
+	switch strings.ToUpper(strings.TrimSpace(str)) {
+	case "", "UTC":
+		return UTC, nil
+	case "TAI":
+		return TAI, nil
+	case "TT", "TDT":
+		return TT, nil
+	case "GPS", "GPST":
+		return GPS, nil
+	case "UT1":
+		return UT1, nil
+	default:
+		return UTC, fmt.Errorf("unknown time scale %s", str)
+	}
+}
+
+func (s Scale) String() string {
+	switch s {
+	case UTC:
+		return "UTC"
+	case TAI:
+		return "TAI"
+	case TT:
+		return "TT"
+	case GPS:
+		return "GPS"
+	case UT1:
+		return "UT1"
+	default:
+		return "unknown"
+	}
+}
+
+// Offset gives the difference (seconds) between s and UTC at w (UTC).
+func (s Scale) Offset(w time.Time) float64 {
+	t := eop.Current()
+	switch s {
+	case TAI:
+		return t.TAI(w)
+	case TT:
+		return t.TAI(w) + deltaTT
+	case GPS:
+		return t.TAI(w) - deltaGPS
+	case UT1:
+		return t.DUT1(w)
+	default:
+		return 0
+	}
+}
+
+// FromUTC gives the time of s at w (UTC).
+func (s Scale) FromUTC(w time.Time) time.Time {
+	return w.Add(seconds(s.Offset(w)))
+}
+
+// ToUTC gives the UTC time of w read in s. The offset is computed again at the
+// UTC time found to cross the leap seconds.
+func (s Scale) ToUTC(w time.Time) time.Time {
+	u := w.Add(-seconds(s.Offset(w)))
+	return w.Add(-seconds(s.Offset(u)))
+}
+
+// Convert gives the time of the scale to of w read in the scale from.
+func Convert(w time.Time, from, to Scale) time.Time {
+	if from == to {
+		return w
+	}
+	return to.FromUTC(from.ToUTC(w))
+}
+
+// Time is the reading of a clock of a time scale.
+type Time struct {
+	When  time.Time
+	Scale Scale
+}
+
+// New gives the time w of s.
+func New(w time.Time, s Scale) Time {
+	return Time{When: w, Scale: s}
+}
+
+// In gives t in the scale s.
+func (t Time) In(s Scale) Time {
+	return Time{When: Convert(t.When, t.Scale, s), Scale: s}
+}
+
+// UTC gives t in UTC.
+func (t Time) UTC() time.Time {
+	return t.Scale.ToUTC(t.When).UTC()
+}
+
+// JD gives the julian date of t in its scale.
+func (t Time) JD() float64 {
+	return coord.JulianDate(t.When)
+}
+
+// MJD gives the modified julian date of t in its scale.
+func (t Time) MJD() float64 {
+	return MJD(t.When)
+}
+
+// JD50 gives the CNES julian date of t in its scale.
+func (t Time) JD50() float64 {
+	return JD50(t.When)
+}
+
+func (t Time) String() string {
+	return t.When.Format("2006-01-02T15:04:05.000000") + " " + t.Scale.String()
+}
+
+// MJD gives the modified julian date (days since 1858-11-17) of w.
+func MJD(w time.Time) float64 {
+	return coord.JulianDate(w) - coord.DeltaMJD
+}
+
+// JD50 gives the CNES julian date (days since 1950-01-01) of w.
+func JD50(w time.Time) float64 {
+	return coord.JulianDate(w) - deltaJD50
+}
+
+// FromMJD gives the time of the modified julian date mjd.
+func FromMJD(mjd float64) time.Time {
+	return coord.FromJulianDate(mjd + coord.DeltaMJD)
+}
+
+// FromJD50 gives the time of the CNES julian date jd.
+func FromJD50(jd float64) time.Time {
+	return coord.FromJulianDate(jd + deltaJD50)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
@@ -0,0 +1,32 @@
+package timescale
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleConvert() {
+	w := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, s := range []Scale{UTC, TAI, TT, GPS} {
+		g := Convert(w, UTC, s)
+		fmt.Printf("%-3s: %s", s, g.Format("2006-01-02T15:04:05.000"))
+		fmt.Println()
+	}
+	fmt.Println(Convert(Convert(w, UTC, GPS), GPS, TT).Format("2006-01-02T15:04:05.000"))
+	// Output:
+	// UTC: 2017-01-01T12:00:00.000
+	// TAI: 2017-01-01T12:00:37.000
+	// TT : 2017-01-01T12:01:09.184
+	// GPS: 2017-01-01T12:00:18.000
+	// 2017-01-01T12:01:09.184
+}
+
+func ExampleTime_JD50() {
+	t := New(time.Date(2004, 4, 6, 7, 51, 28, 386009000, time.UTC), UTC)
+	fmt.Printf("jd: %.6f, mjd: %.6f, jd50: %.6f", t.JD(), t.MJD(), t.JD50())
+	fmt.Println()
+	fmt.Println(t.In(GPS))
+	// Output:
+	// jd: 2453101.827412, mjd: 53101.327412, jd50: 19819.327412
+	// 2004-04-06T07:51:41.386009 GPS
+}
@@ -0,0 +1,240 @@
+package celest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseMode controls how strictly the rows of a TLE are checked.
+type ParseMode int
+
+const (
+	// Strict requires rows of 69 characters with a valid checksum and the
+	// same satellite number on both rows.
+	Strict ParseMode = iota
+	// Lenient accepts rows without checksum or whose trailing spaces have
+	// been removed and does not verify the checksum nor the satellite
+	// numbers.
+	Lenient
+)
+
+type ChecksumError struct {
+	Row  int
+	Want int
+	Got  int
+}
+
+func (e ChecksumError) Error() string {
+	return fmt.Sprintf("invalid checksum on row #%d: %d (expected %d)", e.Row, e.Got, e.Want)
+}
+
+// checksum gives the modulo 10 checksum of the row: the sum of its digits
+// where a minus sign counts for one.
+func checksum(r string) int {
+	var sum int
+	for _, c := range r[:tleLen-1] {
+		switch {
+		case c >= '0' && c <= '9':
+			sum += int(c - '0')
+		case c == '-':
+			sum++
+		}
+	}
+	return sum % 10
+}
+
+func verifyChecksum(r string, row int) error {
+	got, err := strconv.Atoi(r[tleLen-1:])
+	if err != nil {
+		return &ParseError{row: row, cause: fmt.Errorf("missing checksum")}
+	}
+	if want := checksum(r); got != want {
+		return ChecksumError{Row: row, Want: want, Got: got}
+	}
+	return nil
+}
+
+// Format gives the rows of the TLE of the element with their checksum. The
+// rows are built from the fields of the element and not from the rows it was
+// parsed from. An error is returned when the catalog number can not be encoded
+// in the Alpha-5 scheme.
+func (e Element) Format() ([]string, error) {
+	if e.Sid < 0 || e.Sid >= (len(alpha5)+10)*10000 {
+		return nil, fmt.Errorf("catalog number %d can not be encoded in a TLE", e.Sid)
+	}
+	class := e.Class
+	if class == "" {
+		class = "U"
+	}
+	row1 := fmt.Sprintf("1 %5s%1s %-8s %02d%012.8f %s %s %s %1d %4d",
+		formatSid(e.Sid),
+		class,
+		e.Designator,
+		e.Year%100,
+		e.Doy,
+		formatDecimal(e.Mean1*xpdotp*minPerDays),
+		formatExponent(e.Mean2*xpdotp*minPerDays*minPerDays),
+		formatExponent(e.BStar),
+		e.Ephemeris,
+		e.Set%10000,
+	)
+	row2 := fmt.Sprintf("2 %5s %8.4f %8.4f %07.0f %8.4f %8.4f %11.8f%5d",
+		formatSid(e.Sid),
+		formatAngle(e.Inclination),
+		formatAngle(e.Ascension),
+		math.Round(e.Excentricity*1e7),
+		formatAngle(e.Perigee),
+		formatAngle(e.Anomaly),
+		e.Motion*xpdotp,
+		e.Revolution%100000,
+	)
+	rs := []string{row1, row2}
+	for i, r := range rs {
+		rs[i] = r + strconv.Itoa(checksum(r+" "))
+	}
+	return rs, nil
+}
+
+func formatSid(sid int) string {
+	if sid < 100000 {
+		return fmt.Sprintf("%05d", sid)
+	}
+	return FormatCatalog(sid)
+}
+
+func formatAngle(v float64) float64 {
+	v = math.Mod(v*rad2deg, 360)
+	if v < 0 {
+		v += 360
+	}
+	return v
+}
+
+// formatDecimal gives v with 8 decimals and without its leading zero (eg:
+// -.00001207).
+func formatDecimal(v float64) string {
+	sign := " "
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	str := strconv.FormatFloat(v, 'f', 8, 64)
+	return sign + strings.TrimPrefix(str, "0")
+}
+
+// formatExponent gives v with an assumed leading decimal point, 5 significant
+// digits and a power of ten (eg: -11606-4 for -0.11606e-4).
+func formatExponent(v float64) string {
+	if v == 0 {
+		return " 00000-0"
+	}
+	sign := " "
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	exp := int(math.Floor(math.Log10(v))) + 1
+	mant := math.Round(v / math.Pow10(exp) * 1e5)
+	if mant >= 1e5 {
+		mant /= 10
+		exp++
+	}
+	if exp < -9 {
+		return " 00000-0"
+	}
+	esign := "+"
+	if exp < 0 {
+		esign, exp = "-", -exp
+	}
+	return fmt.Sprintf("%s%05.0f%s%d", sign, mant, esign, exp)
+}
+
+// padRow removes the trailing spaces of a row and pads it to the length of a
+// TLE row when shorter.
+func padRow(r string) string {
+	r = strings.TrimRight(r, " \t\r")
+	if n := len(r); n < tleLen {
+		r += strings.Repeat(" ", tleLen-n)
+	}
+	return r
+}
+
+// columns reads the fields of a row of a TLE given their first and last
+// columns (starting at 1). Only the first error is kept.
+type columns struct {
+	row string
+	num int
+	err error
+}
+
+func (c *columns) text(from, to int) string {
+	return strings.TrimSpace(c.row[from-1 : to])
+}
+
+func (c *columns) fail(from, to int, err error) {
+	if c.err == nil && err != nil {
+		c.err = &ParseError{row: c.num, cause: fmt.Errorf("columns %d-%d: %v", from, to, err)}
+	}
+}
+
+func (c *columns) int(from, to int) int {
+	str := c.text(from, to)
+	if str == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(str)
+	c.fail(from, to, err)
+	return v
+}
+
+func (c *columns) float(from, to int) float64 {
+	v, err := strconv.ParseFloat(c.text(from, to), 64)
+	c.fail(from, to, err)
+	return v
+}
+
+func (c *columns) catalog(from, to int) int {
+	v, err := ParseCatalog(c.text(from, to))
+	c.fail(from, to, err)
+	return v
+}
+
+// decimal reads a field with an assumed leading decimal point.
+func (c *columns) decimal(from, to int) float64 {
+	v, err := strconv.ParseFloat("0."+c.text(from, to), 64)
+	c.fail(from, to, err)
+	return v
+}
+
+// exponent reads a field with an assumed leading decimal point and a power of
+// ten (eg: -11606-4 is -0.11606e-4).
+func (c *columns) exponent(from, to int) float64 {
+	str := strings.Replace(c.text(from, to), " ", "+", -1)
+	if str == "" {
+		return 0
+	}
+	ix := strings.LastIndexAny(str, "+-")
+	if ix <= 0 {
+		c.fail(from, to, fmt.Errorf("missing exponent in %s", str))
+		return 0
+	}
+	exp, err := strconv.Atoi(str[ix:])
+	if err != nil {
+		c.fail(from, to, err)
+		return 0
+	}
+	mant := str[:ix]
+	var sign float64 = 1
+	switch mant[0] {
+	case '-':
+		sign, mant = -1, mant[1:]
+	case '+':
+		mant = mant[1:]
+	}
+	if !strings.Contains(mant, ".") {
+		mant = "0." + mant
+	}
+	v, err := strconv.ParseFloat(mant, 64)
+	c.fail(from, to, err)
+	return sign * v * math.Pow10(exp)
+}
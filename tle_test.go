@@ -0,0 +1,68 @@
+package celest
+
+import (
+	"fmt"
+)
+
+func ExampleElement_Format() {
+	var (
+		row1 = "1 25544U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9995"
+		row2 = "2 25544  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139693"
+	)
+	e, err := ParseElement(row1, row2, Strict)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	rs, err := e.Format()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(rs[0])
+	fmt.Println(rs[1])
+	fmt.Println(rs[0] == row1 && rs[1] == row2)
+	// Output:
+	// 1 25544U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9995
+	// 2 25544  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139693
+	// true
+}
+
+func ExampleParseElement_checksum() {
+	var (
+		row1 = "1 25544U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9994"
+		row2 = "2 25544  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139693"
+	)
+	_, err := ParseElement(row1, row2, Strict)
+	fmt.Println(err)
+	_, err = ParseElement(row1, row2, Lenient)
+	fmt.Println(err)
+	// Output:
+	// invalid checksum on row #1: 4 (expected 5)
+	// <nil>
+}
+
+func ExampleParseElement_alpha5() {
+	var (
+		row1 = "1 A0001U 98067A   18304.35926896  .00001207  00000-0  25703-4 0  9996"
+		row2 = "2 A0001  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139694"
+	)
+	e, err := ParseElement(row1, row2, Strict)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(e.Sid, FormatCatalog(e.Sid))
+	rs, _ := e.Format()
+	fmt.Println(rs[1])
+	// Output:
+	// 100001 A0001
+	// 2 A0001  51.6420  60.1332 0004268 356.0118  61.1534 15.53880871139694
+}
+
+func ExampleElement_Format_range() {
+	_, err := Element{Sid: 340000}.Format()
+	fmt.Println(err)
+	// Output:
+	// catalog number 340000 can not be encoded in a TLE
+}
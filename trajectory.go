@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -19,6 +21,7 @@ const (
 var (
 	ErrShortPeriod = errors.New("propagation period shorter than step")
 	ErrBaseTime    = errors.New("no propagation beyond base time")
+	ErrNoElement   = errors.New("no elements in trajectory")
 )
 
 type ParseError struct {
@@ -74,10 +77,57 @@ func (e InvalidLenError) Error() string {
 type Trajectory struct {
 	elements []*Element
 	Base     time.Time
+	Mode     ParseMode
+}
+
+// Elements gives the elements read for the satellite.
+func (t *Trajectory) Elements() []*Element {
+	return t.elements
+}
+
+// Sid gives the satellite number of the trajectory (0 when no elements have
+// been read).
+func (t *Trajectory) Sid() int {
+	if len(t.elements) == 0 {
+		return 0
+	}
+	return t.elements[0].Sid
+}
+
+// Name gives the name of the satellite of the trajectory as found in the title
+// line of its last TLE.
+func (t *Trajectory) Name() string {
+	for i := len(t.elements) - 1; i >= 0; i-- {
+		if n := t.elements[i].Name; n != "" {
+			return n
+		}
+	}
+	return ""
+}
+
+// At gives the position and velocity of the satellite in the TEME frame at w
+// from the last element whose epoch is before w (the first one otherwise).
+func (t *Trajectory) At(w time.Time) (*Point, error) {
+	var e *Element
+	for _, x := range t.elements {
+		switch {
+		case e == nil:
+			e = x
+		case e.When.After(w) && x.When.Before(e.When):
+			e = x
+		case !x.When.After(w) && x.When.After(e.When):
+			e = x
+		}
+	}
+	if e == nil {
+		return nil, ErrNoElement
+	}
+	return e.At(w)
 }
 
 type Info struct {
-	Sid int
+	Sid  int
+	Name string
 
 	When   time.Time
 	Starts time.Time
@@ -93,7 +143,7 @@ func (t *Trajectory) Infos(period, interval time.Duration) []*Info {
 		if period <= 0 {
 			break
 		}
-		i := Info{Sid: e.Sid, When: e.When}
+		i := Info{Sid: e.Sid, Name: e.Name, When: e.When}
 		i.Starts = e.When.Add(interval).Truncate(interval)
 		i.Ends = i.Starts.Add(period)
 		if x < len(t.elements)-1 {
@@ -106,7 +156,172 @@ func (t *Trajectory) Infos(period, interval time.Duration) []*Info {
 	return is
 }
 
-func (t *Trajectory) Predict(p, s time.Duration, saa Shape, delay bool) (<-chan *Result, error) {
+func (t *Trajectory) Predict(p, s time.Duration, saa Shape, delay bool, areas ...Area) (<-chan *Result, error) {
+	ss, err := t.spans(p, s, delay)
+	if err != nil {
+		return nil, err
+	}
+	q := make(chan *Result)
+	go func() {
+		defer close(q)
+		for _, x := range ss {
+			r, _ := x.Predict(x.Period, s, saa, areas...)
+			r.When = x.When
+			q <- r
+			if r.Err != nil {
+				return
+			}
+		}
+	}()
+	return q, nil
+}
+
+// Passes gives the windows of visibility of the satellite from the given
+// station over the period p. Passes split between two consecutive TLE are
+// merged and the passes truncated by the period are dropped.
+func (t *Trajectory) Passes(st Station, p, s time.Duration, delay bool) ([]*Pass, error) {
+	ss, err := t.spans(p, s, delay)
+	if err != nil {
+		return nil, err
+	}
+	var ps []*Pass
+	for _, x := range ss {
+		vs, err := x.Passes(st, x.Period, s)
+		if err != nil {
+			return nil, err
+		}
+		if len(ps) > 0 && len(vs) > 0 {
+			last, first := ps[len(ps)-1], vs[0]
+			if last.EndsAfter && first.StartsBefore && first.AOS.Sub(last.LOS) <= s {
+				last.LOS, last.AzimuthLOS, last.EndsAfter = first.LOS, first.AzimuthLOS, first.EndsAfter
+				if first.Elevation > last.Elevation {
+					last.TCA, last.Azimuth = first.TCA, first.Azimuth
+					last.Elevation, last.Range = first.Elevation, first.Range
+				}
+				vs = vs[1:]
+			}
+		}
+		ps = append(ps, vs...)
+	}
+	vs := ps[:0]
+	for _, p := range ps {
+		if !p.StartsBefore && !p.EndsAfter {
+			vs = append(vs, p)
+		}
+	}
+	return vs, nil
+}
+
+// Crossings gives the entry and exit time of the satellite in the given shape
+// and in each of the named areas over the period p. The crossings truncated by
+// the period are dropped.
+func (t *Trajectory) Crossings(p, s time.Duration, saa Shape, delay bool, areas ...Area) ([]*Event, error) {
+	ss, err := t.spans(p, s, delay)
+	if err != nil {
+		return nil, err
+	}
+	var es []*Event
+	for _, x := range ss {
+		vs, err := x.Crossings(x.Period, s, saa, areas...)
+		if err != nil {
+			return nil, err
+		}
+		es = mergeEvents(es, vs, s)
+	}
+	return completeEvents(es), nil
+}
+
+// Eclipses gives the umbra and penumbra entry and exit time of the satellite
+// over the period p. The eclipses truncated by the period are dropped.
+func (t *Trajectory) Eclipses(p, s time.Duration, delay bool) ([]*Eclipse, error) {
+	ss, err := t.spans(p, s, delay)
+	if err != nil {
+		return nil, err
+	}
+	var es []*Eclipse
+	for _, x := range ss {
+		vs, err := x.Eclipses(x.Period, s)
+		if err != nil {
+			return nil, err
+		}
+		if len(es) > 0 && len(vs) > 0 {
+			last, first := es[len(es)-1], vs[0]
+			if last.EndsAfter && first.StartsBefore && first.PenumbraStarts.Sub(last.PenumbraEnds) <= s {
+				last.PenumbraEnds, last.EndsAfter = first.PenumbraEnds, first.EndsAfter
+				if !first.UmbraEnds.IsZero() {
+					if last.UmbraStarts.IsZero() {
+						last.UmbraStarts = first.UmbraStarts
+					}
+					last.UmbraEnds = first.UmbraEnds
+				}
+				if first.Occulted > last.Occulted {
+					last.Occulted = first.Occulted
+				}
+				vs = vs[1:]
+			}
+		}
+		es = append(es, vs...)
+	}
+	vs := es[:0]
+	for _, e := range es {
+		if !e.StartsBefore && !e.EndsAfter {
+			vs = append(vs, e)
+		}
+	}
+	return vs, nil
+}
+
+// Conjunctions gives the close approaches between the satellites of t and o
+// over the period p with a miss distance below dist (kilometers). The period is
+// split wherever the elements of either trajectory change.
+func (t *Trajectory) Conjunctions(o *Trajectory, p, s time.Duration, delay bool, dist float64) ([]*Conjunction, error) {
+	xs, err := t.spans(p, s, delay)
+	if err != nil {
+		return nil, err
+	}
+	ys, err := o.spans(p, s, delay)
+	if err != nil {
+		return nil, err
+	}
+	var cs []*Conjunction
+	for _, x := range xs {
+		for _, y := range ys {
+			xf, xt := x.Range(x.Period)
+			yf, yt := y.Range(y.Period)
+			if yf.After(xf) {
+				xf = yf
+			}
+			if yt.Before(xt) {
+				xt = yt
+			}
+			if !xf.Before(xt) {
+				continue
+			}
+			vs, err := x.conjunctions(*y.Element, xf, xt.Sub(xf), s, dist)
+			if err != nil {
+				return nil, err
+			}
+			if len(cs) > 0 && len(vs) > 0 {
+				last, first := cs[len(cs)-1], vs[0]
+				if first.TCA.Sub(last.TCA) <= s {
+					if first.Distance < last.Distance {
+						*last = *first
+					}
+					vs = vs[1:]
+				}
+			}
+			cs = append(cs, vs...)
+		}
+	}
+	return cs, nil
+}
+
+type span struct {
+	*Element
+	Period time.Duration
+}
+
+func (t *Trajectory) spans(p, s time.Duration, delay bool) ([]span, error) {
 	if p < s {
 		return nil, ErrShortPeriod
 	}
@@ -134,72 +349,192 @@ func (t *Trajectory) Predict(p, s time.Duration, saa Shape, delay bool) (<-chan
 		}
 		t.elements = append(t.elements[:0], elements...)
 	}
-	q := make(chan *Result)
-	go func() {
-		defer close(q)
-		for i := 0; i < len(t.elements); i++ {
-			if p <= 0 {
-				return
-			}
-			curr := t.elements[i]
-			period := p
-			if len(t.elements) > 1 || delay {
-				if !t.Base.IsZero() && !curr.Base.Equal(t.Base) {
-					curr.Base = curr.When.Add(s).Truncate(s)
-				}
-				if j := i + 1; j < len(t.elements) {
-					next := t.elements[j]
-					period = next.When.Add(s).Truncate(s).Sub(curr.Base)
-					p -= period
-				}
-			}
-			if p < 0 {
-				period += p
+	var ss []span
+	for i := 0; i < len(t.elements); i++ {
+		if p <= 0 {
+			break
+		}
+		curr := t.elements[i]
+		period := p
+		if len(t.elements) > 1 || delay {
+			if !t.Base.IsZero() && !curr.Base.Equal(t.Base) {
+				curr.Base = curr.When.Add(s).Truncate(s)
 			}
-			r, _ := curr.Predict(period, s, saa)
-			r.When = curr.When
-			q <- r
-			if r.Err != nil {
-				return
+			if j := i + 1; j < len(t.elements) {
+				next := t.elements[j]
+				period = next.When.Add(s).Truncate(s).Sub(curr.Base)
+				p -= period
 			}
 		}
-	}()
-	return q, nil
+		if p < 0 {
+			period += p
+		}
+		ss = append(ss, span{Element: curr, Period: period})
+	}
+	return ss, nil
 }
 
+// Scan reads the elements of the satellite sid from r. The format of r (TLE or
+// OMM) is detected from its content.
 func (t *Trajectory) Scan(r io.Reader, sid int, bstar float64) error {
-	s := bufio.NewScanner(r)
+	return t.ScanFormat(r, "", sid, bstar)
+}
+
+// ScanFormat reads the elements of the satellite sid from r given in format
+// (tle, xml, json, csv or kvn). The format is detected from the content of r
+// when empty.
+func (t *Trajectory) ScanFormat(r io.Reader, format string, sid int, bstar float64) error {
+	return t.ScanFilter(r, format, bstar, func(e *Element) bool {
+		return e.Sid == sid
+	})
+}
+
+// ScanFilter reads from r given in format the elements accepted by keep. Once
+// an element has been accepted, only the elements of the same satellite are
+// kept.
+func (t *Trajectory) ScanFilter(r io.Reader, format string, bstar float64, keep func(*Element) bool) error {
+	return scanElements(r, format, t.Mode, func(e *Element, err error) error {
+		if e == nil {
+			switch err.(type) {
+			case InvalidLenError, MissingRowError:
+				return err
+			}
+			return nil
+		}
+		if !keep(e) {
+			return nil
+		}
+		if sid := t.Sid(); sid != 0 && e.Sid != sid {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if math.Abs(e.BStar) > math.Abs(bstar) {
+			return DragError(e.BStar)
+		}
+		t.elements = append(t.elements, e)
+		return nil
+	})
+}
+
+// scanElements reads the elements of r given in format and calls f for each of
+// them with the error met while parsing it. The element is nil when the error
+// occurs before its satellite number is known. The reading stops at the first
+// error returned by f.
+func scanElements(r io.Reader, format string, mode ParseMode, f func(*Element, error) error) error {
+	rs := bufio.NewReader(r)
+	if format == "" {
+		buf, _ := rs.Peek(512)
+		format = DetectFormat(buf)
+	}
+	if strings.ToLower(format) == FormatTLE {
+		return scanTLE(rs, mode, f)
+	}
+	buf, err := ioutil.ReadAll(rs)
+	if err != nil {
+		return err
+	}
+	ms, err := ParseOMM(buf, format)
+	if err != nil {
+		return err
+	}
+	for _, o := range ms {
+		e, perr := o.Element()
+		if perr != nil {
+			e = &Element{Sid: o.Sid}
+		}
+		if err := f(e, perr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanTLE reads the TLE of r and calls f for each of them. A row with an
+// invalid length or a missing row is given to f without element and the
+// reading goes on with the next line starting with "1 ".
+func scanTLE(r io.Reader, mode ParseMode, f func(*Element, error) error) error {
+	var (
+		s      = bufio.NewScanner(r)
+		rows   []string
+		title  string
+		after  bool // title found after the first row
+		broken bool // error already given for the current TLE
+	)
+	skip := func(err error) error {
+		rows, title, broken = rows[:0], "", true
+		return f(nil, err)
+	}
 	for s.Scan() {
-		rs := make([]string, tleRows)
-		for i := 0; i < len(rs); i++ {
-			rs[i] = s.Text()
-			if len(rs[i]) == emptyLen {
-				// skip "empty" line added on celestrack
-				if !s.Scan() {
-					return MissingRowError(i)
+		row := s.Text()
+		switch {
+		case strings.TrimSpace(row) == "":
+			continue
+		case len(rows) == 0 && strings.HasPrefix(row, "2 "):
+			if !broken {
+				if err := skip(MissingRowError(0)); err != nil {
+					return err
 				}
-				rs[i] = s.Text()
 			}
-			if z := len(rs[i]); z != tleLen {
-				return InvalidLenError(z)
+			broken = false
+			continue
+		case isTitle(row, len(rows)):
+			title, after = row, len(rows) > 0
+			continue
+		case len(rows) == 1 && strings.HasPrefix(row, "1 "):
+			t := title
+			if err := skip(MissingRowError(1)); err != nil {
+				return err
 			}
-			if i == 0 && !s.Scan() {
-				return MissingRowError(i)
+			if after {
+				title = t
 			}
 		}
-		e, err := NewElement(rs[0], rs[1])
-		if err != nil && e == nil {
+		if strings.HasPrefix(row, "1 ") {
+			broken = false
+		}
+		if mode == Lenient {
+			row = padRow(row)
+		}
+		if z := len(row); z != tleLen {
+			if err := skip(InvalidLenError(z)); err != nil {
+				return err
+			}
 			continue
 		}
-		if err != nil && e.Sid == sid {
-			return err
+		if rows = append(rows, row); len(rows) < tleRows {
+			continue
 		}
-		if e.Sid == sid && math.Abs(e.BStar) > math.Abs(bstar) {
-			return DragError(e.BStar)
+		e, perr := ParseElement(rows[0], rows[1], mode)
+		if e != nil {
+			e.Name = titleName(title)
 		}
-		if e.Sid == sid {
-			t.elements = append(t.elements, e)
+		rows, title = rows[:0], ""
+		if err := f(e, perr); err != nil {
+			return err
 		}
 	}
-	return s.Err()
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if len(rows) > 0 || title != "" {
+		return f(nil, MissingRowError(len(rows)))
+	}
+	return nil
+}
+
+// isTitle reports whether the row is the (optional) title line of a TLE: any
+// line found in place of the first row but not starting with 1.
+func isTitle(r string, i int) bool {
+	if len(r) == emptyLen {
+		return true
+	}
+	return i == 0 && !strings.HasPrefix(r, "1 ")
+}
+
+// titleName gives the name of the satellite from the title line of a TLE with
+// its trailing spaces and the line number of the three-line format removed.
+func titleName(title string) string {
+	return strings.TrimSpace(strings.TrimPrefix(title, "0 "))
 }